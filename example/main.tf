@@ -55,12 +55,6 @@ resource "vbridge_virtual_machine" "example" {
   hosting_location_default_network = "CHC-CUST-SDC-WAN"
   backup_type                     = "vBackupDisk"
   # backup_type                     = "vBackupNone"
-
-   lifecycle {
-    ignore_changes = [
-      guest_os_id
-    ]
-   }
 }
 
 # Performance Disk
@@ -82,4 +76,12 @@ resource "vbridge_virtual_machine_additionaldisk" "disk2" {
 #   vm_id = resource.vbridge_virtual_machine.example.vm_id
 #   storage_profile = "vStorageT3"
 #   capacity = 35
-# }
\ No newline at end of file
+# }
+# # Pre-patch Snapshot
+# resource "vbridge_virtual_machine_snapshot" "pre_patch" {
+#   vm_id          = resource.vbridge_virtual_machine.example.vm_id
+#   name           = "pre-patch"
+#   description    = "Taken before monthly patching"
+#   include_memory = true
+#   warn_after     = "48h"
+# }
@@ -1,331 +1,101 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
+	"flag"
 	"log"
-	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
-	"path"
-	"strings"
-	"sync"
+	"time"
 
-	"github.com/google/uuid"
-)
-
-type VirtualMachine struct {
-	ClientId            int      `json:"clientId"`
-	Name                string   `json:"name"`
-	Template            string   `json:"template"`
-	GuestOsId           string   `json:"guestOsId"`
-	Cores               int      `json:"cores"`
-	MemorySize          int      `json:"memorySize"`
-	OperatingSystemDisk Disk     `json:"operatingSystemDisk"`
-	AdditionalDisks     []Disk   `json:"additionalDisks,omitempty"`
-	IsoFile             string   `json:"isoFile,omitempty"`
-	QuoteItem           Quote    `json:"quoteItem,omitempty"`
-	BackupType          string   `json:"backupType"`
-	Id                  int      `json:"id"`
-	HostingLocation     Location `json:"hostingLocation"`
-	MoRef               string   `json:"moRef"`
-}
-
-type Disk struct {
-	Capacity       int    `json:"capacity"`
-	StorageProfile string `json:"storageProfile"`
-}
-
-type Quote struct{}
-
-type Location struct {
-	Id             string `json:"id"`
-	Name           string `json:"name"`
-	DefaultNetwork string `json:"defaultNetwork"`
-}
-
-var (
-	vmMutex sync.Mutex
-	vms     = make(map[int]VirtualMachine)
-)
-
-const (
-	apiKey = "yourapikeygoeshere"
-	user   = "you-users@yourcompany.com"
+	"localhost-api/mockapi"
 )
 
 func main() {
-	http.HandleFunc("/api/Provisioning/VirtualMachine", provisionVMHandler)
-	http.HandleFunc("/api/client/virtualresources/", getAllVMsHandler)
-	http.HandleFunc("/api/VirtualResource/Detailed/", getVMDetailedByIDHandler)
-
-	log.Println("Starting server on :8087")
-	log.Fatal(http.ListenAndServe(":8087", nil))
-}
-
-func authenticate(r *http.Request) bool {
-	apiKeyHeader := r.Header.Get("Authorization")
-	userHeader := r.Header.Get("x-mcs-user")
-
-	return apiKeyHeader == fmt.Sprintf("Bearer %s", apiKey) && userHeader == user
-}
-
-func hasMissingRequiredFields(vm VirtualMachine) (bool, string) {
-	fields := []struct {
-		value string
-		name  string
-	}{
-		{fmt.Sprint(vm.ClientId), "ClientId"},
-		{vm.Name, "Name"},
-		{vm.GuestOsId, "GuestOsId"},
-		{fmt.Sprint(vm.Cores), "Cores"},
-		{fmt.Sprint(vm.MemorySize), "MemorySize"},
-		{vm.OperatingSystemDisk.StorageProfile, "OperatingSystemDisk.StorageProfile"},
-		{vm.HostingLocation.Id, "HostingLocation.Id"},
-		{vm.HostingLocation.Name, "HostingLocation.Name"},
-		{vm.HostingLocation.DefaultNetwork, "HostingLocation.DefaultNetwork"},
-		{vm.BackupType, "BackupType"},
-	}
-
-	for _, field := range fields {
-		if field.value == "" || field.value == "0" {
-			return true, field.name
+	listen := flag.String("listen", ":8087", "address to listen on")
+	dataDir := flag.String("data-dir", "", "directory to store VMs in; without it VMs are only kept in memory")
+	inMemory := flag.Bool("in-memory", false, "keep VMs in memory even if -data-dir is set")
+	seed := flag.String("seed", "", "JSON file of VMs to load at start and on POST /_mock/reset")
+	record := flag.String("record", "", "proxy to this vBridge API URL, recording to -cassette")
+	replay := flag.String("replay", "", "serve the responses recorded in this cassette")
+	cassette := flag.String("cassette", "cassette.json", "file -record writes to")
+	tenantsFlag := flag.String("tenants", os.Getenv("MOCK_API_TENANTS"),
+		"comma separated credentials to accept as <api key>:<user>:<client id>[;<client id>...] (env MOCK_API_TENANTS)")
+
+	var faults mockapi.Faults
+	flag.Func("latency", "latency to add to every API request, e.g. 500ms", durationFlag(&faults.Latency))
+	flag.Func("list-delay", "how long new VMs are hidden from the virtual resource list", durationFlag(&faults.ListDelay))
+	flag.Func("detailed-delay", "how long the detailed endpoint returns 404 for new VMs", durationFlag(&faults.DetailedDelay))
+	flag.Func("task-duration", "how long tasks such as restores run before completing", durationFlag(&faults.TaskDuration))
+	flag.IntVar(&faults.DropDisks, "drop-disks", 0, "number of detailed responses to leave additional disks out of")
+	flag.Func("fault", "fail requests as [<method> ]<path>=<status>[x<count>], may be repeated", func(s string) error {
+		rule, err := mockapi.ParseFaultRule(s)
+		if err != nil {
+			return err
 		}
-	}
-
-	return false, ""
-}
-
-func provisionVMHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
-		return
-	}
-
-	if !authenticate(r) {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	var vm VirtualMachine
-	err := json.NewDecoder(r.Body).Decode(&vm)
-	if err != nil {
-		handleError(w, fmt.Sprintf("Error parsing VM file %s", err), http.StatusBadRequest)
-		return
-	}
-
-	if missing, field := hasMissingRequiredFields(vm); missing {
-		handleError(w, fmt.Sprintf("Missing required field: %s", field), http.StatusBadRequest)
-		return
-	}
-
-	vmID := rand.Int()
-	vm.Id = vmID
-	vm.MoRef = uuid.New().String()
-
-	if vm.Template == "Windows2022_Standard_30GB" {
-		vm.OperatingSystemDisk.Capacity = 30
-	}
-
-	vmMutex.Lock()
-	vms[vmID] = vm
-	vmMutex.Unlock()
-
-	if err := saveVMToFile(vmID, vm); err != nil {
-		handleError(w, "Error saving VM details", http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-}
-
-func getAllVMsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
-		return
-	}
-
-	if !authenticate(r) {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	allVMs, err := loadAllVMs()
-	if err != nil {
-		handleError(w, "Error loading VMs", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(allVMs)
-}
-
-func getVMDetailedByIDHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
-		return
-	}
-
-	vmID := path.Base(r.URL.Path)
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) != 5 {
-		handleError(w, "Invalid URL path", http.StatusBadRequest)
-		return
-	}
-
-	vm, err := loadVMFromFile(vmID)
-	if err != nil {
-		if os.IsNotExist(err) {
-			handleError(w, "VM not found", http.StatusNotFound)
-		} else {
-			handleError(w, "Error reading VM file", http.StatusInternalServerError)
+		faults.Rules = append(faults.Rules, rule)
+		return nil
+	})
+	flag.Parse()
+
+	var handler http.Handler
+	switch {
+	case *record != "":
+		target, err := url.Parse(*record)
+		if err != nil {
+			log.Fatal(err)
 		}
-		return
-	}
-
-	response := createVMDetailResponse(vm)
-	log.Printf("Vm Detail Response %s", response)
-
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(response)
-	if err != nil {
-		handleError(w, "Error encoding JSON response", http.StatusInternalServerError)
-	}
-}
+		handler = mockapi.NewRecorder(target, *cassette).Handler()
+		log.Printf("Recording %s to %s", target, *cassette)
 
-func handleError(w http.ResponseWriter, message string, statusCode int) {
-	log.Println(message)
-	http.Error(w, message, statusCode)
-}
+	case *replay != "":
+		recorded, err := mockapi.LoadCassette(*replay)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handler = mockapi.NewReplayer(recorded).Handler()
+		log.Printf("Replaying %d interactions from %s", len(recorded.Interactions), *replay)
 
-func saveVMToFile(vmID int, vm VirtualMachine) error {
-	file, err := json.MarshalIndent(vm, "", " ")
-	if err != nil {
-		return err
+	default:
+		server, err := newServer(*tenantsFlag, *dataDir, *inMemory, *seed, faults)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handler = server.Handler()
 	}
 
-	fileName := fmt.Sprintf("%d.json", vmID)
-	log.Printf("Saving VM file %d.json", vmID)
-	return os.WriteFile(fileName, file, 0644)
+	log.Printf("Starting server on %s", *listen)
+	log.Fatal(http.ListenAndServe(*listen, handler))
 }
 
-func loadAllVMs() ([]map[string]interface{}, error) {
-	var allVMs []map[string]interface{}
-	log.Printf("Reading all json files")
-	files, err := os.ReadDir(".")
+func newServer(tenantsFlag, dataDir string, inMemory bool, seed string, faults mockapi.Faults) (*mockapi.Server, error) {
+	tenants, err := mockapi.ParseTenants(tenantsFlag)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
-			vm, err := loadVMFromFile(strings.TrimSuffix(file.Name(), ".json"))
-			log.Printf("Reading VM file %s", file.Name())
-			if err != nil {
-				log.Printf("Error parsing VM file %s: %v\n", file.Name(), err)
-				continue
-			}
-
-			vmMap := map[string]interface{}{
-				"clientId":            vm.ClientId,
-				"name":                vm.Name,
-				"template":            vm.Template,
-				"guestOsId":           vm.GuestOsId,
-				"cores":               vm.Cores,
-				"memorySize":          vm.MemorySize,
-				"operatingSystemDisk": vm.OperatingSystemDisk,
-				"additionalDisks":     vm.AdditionalDisks,
-				"isoFile":             vm.IsoFile,
-				"quoteItem":           vm.QuoteItem,
-				"backupType":          vm.BackupType,
-				"id":                  vm.Id,
-				"hostingLocation":     vm.HostingLocation.Name,
-			}
-
-			allVMs = append(allVMs, vmMap)
-		}
-	}
-	return allVMs, nil
-}
-
-func loadVMFromFile(vmID string) (VirtualMachine, error) {
-	fileName := fmt.Sprintf("%s.json", vmID)
-	data, err := os.ReadFile(fileName)
-	log.Printf("Reading json file %s", fileName)
-	if err != nil {
-		return VirtualMachine{}, err
+	opts := mockapi.Options{DataDir: dataDir, Tenants: tenants, Faults: faults}
+	if inMemory {
+		opts.DataDir = ""
 	}
 
-	var vm VirtualMachine
-	err = json.Unmarshal(data, &vm)
-	if err != nil {
-		return VirtualMachine{}, err
+	if seed != "" {
+		opts.Seed, err = mockapi.LoadSeed(seed)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return vm, nil
-}
-
-func createVMDetailResponse(vm VirtualMachine) map[string]interface{} {
-	log.Printf("Creating VM Detail Response")
-	return map[string]interface{}{
-		"clientId": vm.ClientId,
-		"specification": map[string]interface{}{
-			"cores":               vm.Cores,
-			"sockets":             1,
-			"memoryGb":            vm.MemorySize,
-			"moRef":               vm.MoRef,
-			"virtualDisks":        generateVirtualDisks(vm.OperatingSystemDisk, vm.AdditionalDisks),
-			"backupType":          vm.BackupType,
-			"hostingLocationName": vm.HostingLocation.Name,
-			"hostingLocationId":   vm.HostingLocation.Id,
-		},
-		"id":                  vm.Id,
-		"name":                vm.Name,
-		"lastVirtualDisks":    1,
-		"lastCPU":             vm.Cores,
-		"lastMemory":          vm.MemorySize,
-		"hostingLocation":     vm.HostingLocation.Name,
-		"hostingLocationType": "DefaultType",
-		"annotation":          "Default annotation",
-	}
+	return mockapi.New(opts)
 }
 
-func generateVirtualDisks(operatingSystemDisk Disk, additionalDisks []Disk) []map[string]interface{} {
-	var virtualDisks []map[string]interface{}
-	log.Printf("Generating Virtual Disks for VM Detail Response")
-
-	// Add the operating system disk as the first virtual disk
-	virtualDisks = append(virtualDisks, map[string]interface{}{
-		"moRef":              uuid.New().String(),
-		"capacity":           operatingSystemDisk.Capacity,
-		"vmfs":               operatingSystemDisk.StorageProfile,
-		"slotInfo":           "slotInfo",
-		"tier":               "tier",
-		"name":               "OperatingSystemDisk",
-		"capacityDesciption": "Operating system disk",
-		"vDiskID":            "vDiskID",
-		"filename":           "filename",
-		"friendlyName":       "OS Disk",
-	})
-
-	// Add additional disks
-	for _, disk := range additionalDisks {
-		virtualDisk := map[string]interface{}{
-			"moRef":              uuid.New().String(),
-			"capacity":           disk.Capacity,
-			"vmfs":               disk.StorageProfile,
-			"slotInfo":           "slotInfo",
-			"tier":               "tier",
-			"name":               "AdditionalDisk",
-			"capacityDesciption": "Additional disk",
-			"vDiskID":            "vDiskID",
-			"filename":           "filename",
-			"friendlyName":       "Additional Disk",
+func durationFlag(d *mockapi.Duration) func(string) error {
+	return func(s string) error {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return err
 		}
-		virtualDisks = append(virtualDisks, virtualDisk)
-		log.Printf("Added virtual disk: %+v", virtualDisk)
+		*d = mockapi.Duration(parsed)
+		return nil
 	}
-
-	log.Printf("Generated Virtual Disks: %v", virtualDisks)
-	return virtualDisks
 }
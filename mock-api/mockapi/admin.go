@@ -0,0 +1,70 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// The /_mock endpoints aren't part of the vBridge API. They let tests reset,
+// inspect and inject faults into the mock, so they don't require credentials
+// and faults are never applied to them.
+
+// loadSeed saves the seed VMs to the store. The caller must hold vmMutex or
+// have exclusive access to the server.
+func (s *Server) loadSeed() error {
+	for _, vm := range s.seed {
+		if err := s.store.save(vm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) resetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	if err := s.store.reset(); err != nil {
+		handleError(w, "Error resetting VMs", http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.loadSeed(); err != nil {
+		handleError(w, "Error loading seed VMs", http.StatusInternalServerError)
+		return
+	}
+
+	s.setFaults(s.initialFaults)
+	s.resetTasks()
+
+	log.Printf("Reset mock state with %d seed VMs", len(s.seed))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) stateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.vmMutex.Lock()
+	vms, err := s.store.list()
+	s.vmMutex.Unlock()
+	if err != nil {
+		handleError(w, "Error loading VMs", http.StatusInternalServerError)
+		return
+	}
+
+	if vms == nil {
+		vms = []VirtualMachine{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vms)
+}
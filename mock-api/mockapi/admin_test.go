@@ -0,0 +1,59 @@
+package mockapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestResetHandler(t *testing.T) {
+	// Given
+	seeded := testVM("seeded")
+	seeded.Id = 500
+	faults := Faults{Rules: []FaultRule{{Path: "/api/virtualresource/", Status: http.StatusInternalServerError}}}
+	server, ts := newTestServer(t, Options{Seed: []VirtualMachine{seeded}, Faults: faults})
+
+	provision(t, ts.URL, testVM("provisioned"))
+	server.setFaults(Faults{})
+
+	// When
+	resp := send(t, newRequest(t, http.MethodPost, ts.URL+"/_mock/reset", nil))
+
+	// Then
+	expectStatus(t, resp, http.StatusNoContent)
+	vms := state(t, ts.URL)
+	if len(vms) != 1 || vms[0].Id != 500 {
+		t.Errorf("state after reset = %+v, want only the seed VM", vms)
+	}
+	if got := server.currentFaults(); len(got.Rules) != 1 {
+		t.Errorf("faults after reset = %+v, want the initial faults", got)
+	}
+}
+
+func TestStateHandler_Empty(t *testing.T) {
+	// Given
+	_, ts := newTestServer(t, Options{})
+
+	// When
+	resp := send(t, newRequest(t, http.MethodGet, ts.URL+"/_mock/state", nil))
+
+	// Then
+	expectStatus(t, resp, http.StatusOK)
+	var vms []VirtualMachine
+	decode(t, resp, &vms)
+	if vms == nil || len(vms) != 0 {
+		t.Errorf("state = %#v, want an empty array", vms)
+	}
+}
+
+func TestMockEndpoints_RejectWrongMethods(t *testing.T) {
+	// Given
+	_, ts := newTestServer(t, Options{})
+
+	// When
+	reset := send(t, newRequest(t, http.MethodGet, ts.URL+"/_mock/reset", nil))
+	state := send(t, newRequest(t, http.MethodPost, ts.URL+"/_mock/state", nil))
+
+	// Then
+	expectStatus(t, reset, http.StatusMethodNotAllowed)
+	expectStatus(t, state, http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,33 @@
+package mockapi
+
+import "net/http"
+
+type updateAnnotationPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Annotation        string `json:"annotation"`
+}
+
+func (s *Server) updateAnnotationHandler(w http.ResponseWriter, r *http.Request) {
+	var payload updateAnnotationPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	vm.Annotation = payload.Annotation
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,131 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Tenant is a set of credentials accepted by the mock and the client IDs
+// they may manage.
+type Tenant struct {
+	APIKey    string
+	User      string
+	ClientIds []int
+}
+
+// DefaultTenant matches example/mock.tfvars.
+var DefaultTenant = Tenant{
+	APIKey:    "yourapikeygoeshere",
+	User:      "you-users@yourcompany.com",
+	ClientIds: []int{599},
+}
+
+// ParseTenants parses a comma separated list of tenants, each written as
+// <api key>:<user>:<client id>[;<client id>...], e.g.
+// "key1:a@example.com:599,key2:b@example.com:600;601".
+func ParseTenants(s string) ([]Tenant, error) {
+	var tenants []Tenant
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid tenant %q, expected <api key>:<user>:<client id>", entry)
+		}
+
+		tenant := Tenant{APIKey: parts[0], User: parts[1]}
+		for _, id := range strings.Split(parts[2], ";") {
+			clientId, err := strconv.Atoi(id)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client id %q for tenant %s: %w", id, parts[1], err)
+			}
+			tenant.ClientIds = append(tenant.ClientIds, clientId)
+		}
+
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, nil
+}
+
+func (t Tenant) ownsClient(clientId int) bool {
+	for _, id := range t.ClientIds {
+		if id == clientId {
+			return true
+		}
+	}
+	return false
+}
+
+// problem is the RFC 7807 body the vBridge API returns for errors.
+type problem struct {
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Status  int    `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+	TraceId string `json:"traceId"`
+}
+
+var problemTypes = map[int]string{
+	http.StatusUnauthorized: "https://tools.ietf.org/html/rfc7235#section-3.1",
+	http.StatusForbidden:    "https://tools.ietf.org/html/rfc7231#section-6.5.3",
+}
+
+func writeProblem(w http.ResponseWriter, statusCode int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+	if statusCode == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "apiKey")
+	}
+	w.WriteHeader(statusCode)
+
+	json.NewEncoder(w).Encode(problem{
+		Type:    problemTypes[statusCode],
+		Title:   http.StatusText(statusCode),
+		Status:  statusCode,
+		Detail:  detail,
+		TraceId: fmt.Sprintf("00-%s-0000000000000000-00", strings.ReplaceAll(uuid.New().String(), "-", "")),
+	})
+}
+
+// authenticate returns the tenant for the request's credentials. If there is
+// none it writes a 401, or a 403 if the key is known but the user isn't.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (Tenant, bool) {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "apiKey ")
+	if !ok || key == "" {
+		writeProblem(w, http.StatusUnauthorized, "")
+		return Tenant{}, false
+	}
+
+	for _, tenant := range s.tenants {
+		if tenant.APIKey != key {
+			continue
+		}
+
+		if r.Header.Get("x-mcs-user") != tenant.User {
+			writeProblem(w, http.StatusForbidden, "The user is not permitted to use this API key.")
+			return Tenant{}, false
+		}
+		return tenant, true
+	}
+
+	writeProblem(w, http.StatusUnauthorized, "")
+	return Tenant{}, false
+}
+
+// authorizeClient writes a 403 and returns false unless tenant may manage
+// clientId.
+func authorizeClient(w http.ResponseWriter, tenant Tenant, clientId int) bool {
+	if !tenant.ownsClient(clientId) {
+		writeProblem(w, http.StatusForbidden, fmt.Sprintf("You do not have access to client %d.", clientId))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package mockapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseTenants(t *testing.T) {
+	// Given
+	s := "key1:a@example.com:599, key2:b@example.com:600;601"
+
+	// When
+	tenants, err := ParseTenants(s)
+
+	// Then
+	if err != nil {
+		t.Fatalf("ParseTenants() error = %v", err)
+	}
+	want := []Tenant{
+		{APIKey: "key1", User: "a@example.com", ClientIds: []int{599}},
+		{APIKey: "key2", User: "b@example.com", ClientIds: []int{600, 601}},
+	}
+	if !reflect.DeepEqual(tenants, want) {
+		t.Errorf("ParseTenants() = %+v, want %+v", tenants, want)
+	}
+}
+
+func TestParseTenants_Invalid(t *testing.T) {
+	for _, s := range []string{"key1", "key1:a@example.com", ":a@example.com:599", "key1:a@example.com:abc"} {
+		// When
+		_, err := ParseTenants(s)
+
+		// Then
+		if err == nil {
+			t.Errorf("ParseTenants(%q) error = nil, want an error", s)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	other := Tenant{APIKey: "otherkey", User: "other@example.com", ClientIds: []int{600}}
+
+	tests := []struct {
+		name       string
+		key        string
+		user       string
+		status     int
+		challenged bool
+	}{
+		{name: "no key", status: http.StatusUnauthorized, challenged: true},
+		{name: "unknown key", key: "wrong", user: DefaultTenant.User, status: http.StatusUnauthorized, challenged: true},
+		{name: "wrong user", key: DefaultTenant.APIKey, user: other.User, status: http.StatusForbidden},
+		{name: "other tenant's client", key: other.APIKey, user: other.User, status: http.StatusForbidden},
+		{name: "valid", key: DefaultTenant.APIKey, user: DefaultTenant.User, status: http.StatusOK},
+	}
+
+	_, ts := newTestServer(t, Options{Tenants: []Tenant{DefaultTenant, other}})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			req := newRequest(t, http.MethodGet, ts.URL+"/api/client/virtualresources/599", nil)
+			if tt.key != "" {
+				req.Header.Set("Authorization", "apiKey "+tt.key)
+			}
+			req.Header.Set("x-mcs-user", tt.user)
+
+			// When
+			resp := send(t, req)
+
+			// Then
+			expectStatus(t, resp, tt.status)
+			if got := resp.Header.Get("WWW-Authenticate") != ""; got != tt.challenged {
+				t.Errorf("WWW-Authenticate present = %t, want %t", got, tt.challenged)
+			}
+			if tt.status == http.StatusOK {
+				return
+			}
+
+			if got := resp.Header.Get("Content-Type"); got != "application/problem+json; charset=utf-8" {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+			var body problem
+			decode(t, resp, &body)
+			if body.Status != tt.status || body.Title != http.StatusText(tt.status) || body.Type != problemTypes[tt.status] || body.TraceId == "" {
+				t.Errorf("problem = %+v, want status %d", body, tt.status)
+			}
+		})
+	}
+}
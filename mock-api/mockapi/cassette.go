@@ -0,0 +1,54 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Cassette is a sequence of recorded API interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and the response the API gave.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"contentType,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	// Text holds bodies that aren't JSON.
+	Text string `json:"text,omitempty"`
+}
+
+// LoadCassette reads a cassette written by a Recorder.
+func LoadCassette(fileName string) (Cassette, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return Cassette{}, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return Cassette{}, fmt.Errorf("error parsing cassette %s: %w", fileName, err)
+	}
+	return cassette, nil
+}
+
+func (c Cassette) save(fileName string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, data, 0644)
+}
@@ -0,0 +1,67 @@
+package mockapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type updateCPUPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Cores             int    `json:"cores"`
+	CoresPerSocket    int    `json:"coresPerSocket"`
+}
+
+// topology returns the cores per socket and sockets of a VM. Cores is the
+// total vCPU count; VMs provisioned without CoresPerSocket have one socket.
+func topology(vm VirtualMachine) (int, int) {
+	coresPerSocket := vm.CoresPerSocket
+	if coresPerSocket <= 0 || vm.Cores%coresPerSocket != 0 {
+		coresPerSocket = vm.Cores
+	}
+	if coresPerSocket == 0 {
+		return 0, 0
+	}
+	return coresPerSocket, vm.Cores / coresPerSocket
+}
+
+// updateCPUHandler changes a VM's vCPU count and topology. As in vSphere,
+// CPUs can be hot added to a running VM but not removed, and the topology
+// can only change while it is powered off.
+func (s *Server) updateCPUHandler(w http.ResponseWriter, r *http.Request) {
+	var payload updateCPUPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	if payload.Cores <= 0 || payload.CoresPerSocket <= 0 || payload.Cores%payload.CoresPerSocket != 0 {
+		handleError(w, fmt.Sprintf("Cores (%d) must be a positive multiple of CoresPerSocket (%d)", payload.Cores, payload.CoresPerSocket), http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	coresPerSocket, _ := topology(vm)
+	if vm.PowerState != powerStateOff && (payload.CoresPerSocket != coresPerSocket || payload.Cores < vm.Cores) {
+		handleError(w, "VM must be powered off to remove CPUs or change its CPU topology", http.StatusConflict)
+		return
+	}
+
+	log.Printf("Updating CPU of VM %d: %d x %d -> %d x %d", vm.Id, vm.Cores/coresPerSocket, coresPerSocket, payload.Cores/payload.CoresPerSocket, payload.CoresPerSocket)
+	vm.Cores = payload.Cores
+	vm.CoresPerSocket = payload.CoresPerSocket
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,238 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+)
+
+// tierNames maps the storage profiles used when provisioning to the tier
+// names the detailed endpoint reports.
+var tierNames = map[string]string{
+	"vStorageT1": "Performance",
+	"vStorageT2": "General Purpose",
+	"vStorageT3": "Low Use",
+}
+
+// datastores names a datastore for each storage profile, for disks' vmfs
+// paths.
+var datastores = map[string]string{
+	"vStorageT1": "SANCHC2SAN6-Perf-4",
+	"vStorageT2": "SANCHC2SAN6-GP-2",
+	"vStorageT3": "SANCHC2SAN6-LowUse-1",
+}
+
+// Daily sell costs used to derive a VM's cost fields.
+const (
+	costPerCoreDaily     = 0.85
+	costPerMemoryGbDaily = 0.55
+)
+
+var costPerDiskGbDaily = map[string]float64{
+	"vStorageT1": 0.0188,
+	"vStorageT2": 0.0102,
+	"vStorageT3": 0.0061,
+}
+
+var guestOSNames = map[string]string{
+	"windows2019srv_64Guest":     "Microsoft Windows Server 2019 (64-bit)",
+	"windows2019srvNext_64Guest": "Microsoft Windows Server 2022 (64-bit)",
+	"windows9Server64Guest":      "Microsoft Windows Server 2016 (64-bit)",
+	"ubuntu64Guest":              "Ubuntu Linux (64-bit)",
+	"rhel8_64Guest":              "Red Hat Enterprise Linux 8 (64-bit)",
+}
+
+// windowsLicenses are the licenses offered for Windows guests.
+var windowsLicenses = []string{"Windows Server 2016", "Windows Server 2019", "Windows Server 2022"}
+
+// decimal writes n with a decimal point, as the API does for capacities.
+func decimal(n int) json.Number {
+	return json.Number(fmt.Sprintf("%d.0", n))
+}
+
+// money rounds a cost to the 10 decimal places the API uses.
+func money(cost float64) json.Number {
+	return json.Number(fmt.Sprintf("%.10f", cost))
+}
+
+// hash returns a stable number derived from s, used for generated IDs.
+func hash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// groupThousands formats n with comma separators, e.g. 104,857,600.
+func groupThousands(n int) string {
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func createVMDetailResponse(vm VirtualMachine) map[string]interface{} {
+	log.Printf("Creating VM Detail Response")
+
+	powerState := vm.PowerState
+	if powerState == "" {
+		powerState = powerStateOn
+	}
+
+	var mountedISO interface{}
+	if vm.IsoFile != "" {
+		mountedISO = vm.IsoFile
+	}
+
+	firstSeen := vm.ProvisionedAt
+	if firstSeen.IsZero() {
+		firstSeen = time.Now()
+	}
+
+	disks := append([]Disk{vm.OperatingSystemDisk}, vm.AdditionalDisks...)
+	network := defaultNetwork(vm)
+	coresPerSocket, sockets := topology(vm)
+
+	return map[string]interface{}{
+		"clientId": vm.ClientId,
+		"licenses": generateLicenses(vm),
+		"specification": map[string]interface{}{
+			"healthState":          "green",
+			"powerState":           powerState,
+			"cores":                coresPerSocket,
+			"sockets":              sockets,
+			"memoryGb":             memoryMb(vm) / 1024,
+			"memoryMb":             memoryMb(vm),
+			"moRef":                vm.MoRef,
+			"virtualDisks":         generateVirtualDisks(vm.Name, disks),
+			"instantRecoveryDisks": generateInstantRecoveryDisks(vm),
+			"availableNetworks":    []interface{}{network},
+			"networkDevices":       generateNetworkDevices(vm, network, powerState),
+			"backupType":           vm.BackupType,
+			"backupAA":             false,
+			"backupLastDate":       nil,
+			"backupLastJob":        nil,
+			"mountedISO":           mountedISO,
+			"hostingLocationName":  "",
+			"hostingLocationId":    vm.HostingLocation.Id,
+		},
+		"promoUntil":          nil,
+		"id":                  vm.Id,
+		"name":                vm.Name,
+		"healthState":         "green",
+		"powerState":          "powered" + powerState,
+		"hasSnapshot":         len(vm.Snapshots) > 0,
+		"healthFetched":       time.Now().Format(time.RFC3339),
+		"firstSeen":           firstSeen.Format(time.RFC3339),
+		"lastVirtualDisks":    len(disks),
+		"lastCPU":             vm.Cores,
+		"lastMemory":          vm.MemorySize,
+		"hostingLocation":     vm.HostingLocation.Name,
+		"hostingLocationType": "",
+		"guestHostname":       fmt.Sprintf("%s.local", vm.Name),
+		"guestNetworkAddress": guestNetworkAddress(vm, powerState),
+		"guestOS":             guestOSNames[vm.GuestOsId],
+		"lastBackup":          nil,
+		"acType":              "full",
+		"blType":              "billview",
+		"sellCostDaily":       money(dailyCost(vm, disks)),
+		"sellCostMonthly":     money(dailyCost(vm, disks) * 365 / 12),
+		"sellCostHourly":      money(dailyCost(vm, disks) / 24),
+		"margin":              json.Number("0.0"),
+		"annotation":          vm.Annotation,
+	}
+}
+
+func generateVirtualDisks(vmName string, disks []Disk) []map[string]interface{} {
+	var virtualDisks []map[string]interface{}
+	log.Printf("Generating Virtual Disks for VM Detail Response")
+
+	for i, disk := range disks {
+		fileName := vmName
+		if i > 0 {
+			fileName = fmt.Sprintf("%s_%d", vmName, i)
+		}
+
+		virtualDisks = append(virtualDisks, map[string]interface{}{
+			"moRef":              disk.MoRef,
+			"capacity":           decimal(disk.Capacity),
+			"vmfs":               fmt.Sprintf("[%s] %s/%s.vmdk", datastores[disk.StorageProfile], vmName, fileName),
+			"slotInfo":           fmt.Sprintf("Slot 0:%d", i),
+			"tier":               tierNames[disk.StorageProfile],
+			"name":               fmt.Sprintf("Hard disk %d", i+1),
+			"capacityDesciption": fmt.Sprintf("%s KB", groupThousands(disk.Capacity*1024*1024)),
+			"vDiskID":            nil,
+			"filename":           nil,
+			"friendlyName":       nil,
+		})
+	}
+
+	log.Printf("Generated Virtual Disks: %v", virtualDisks)
+	return virtualDisks
+}
+
+func defaultNetwork(vm VirtualMachine) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              fmt.Sprintf("DistributedVirtualPortgroup-dvportgroup-%d", hash(vm.HostingLocation.DefaultNetwork)%10000),
+		"name":            vm.HostingLocation.DefaultNetwork,
+		"vlan":            0,
+		"hostingLocation": vm.HostingLocation.Id,
+	}
+}
+
+// generateNetworkDevices returns a single NIC on the default network, which
+// is what a VM is provisioned with.
+func generateNetworkDevices(vm VirtualMachine, network map[string]interface{}, powerState string) []map[string]interface{} {
+	mac := hash(fmt.Sprint(vm.Id))
+	return []map[string]interface{}{
+		{
+			"name":           "Network adapter 1",
+			"moRef":          "4000",
+			"networkName":    network["name"],
+			"macAddress":     fmt.Sprintf("00:50:56:%02x:%02x:%02x", byte(mac>>16)&0x3f, byte(mac>>8), byte(mac)),
+			"connected":      powerState == powerStateOn,
+			"startConnected": true,
+			"networkId":      network["id"],
+		},
+	}
+}
+
+func generateLicenses(vm VirtualMachine) []map[string]interface{} {
+	licenses := []map[string]interface{}{}
+	if !isWindows(vm) {
+		return licenses
+	}
+
+	for _, name := range windowsLicenses {
+		licenseId, licenseKey := 0, ""
+		if name == vm.License {
+			licenseId, licenseKey = assignedLicense(vm)
+		}
+
+		licenses = append(licenses, map[string]interface{}{
+			"licenseId":  licenseId,
+			"name":       name,
+			"licenseKey": licenseKey,
+		})
+	}
+	return licenses
+}
+
+func guestNetworkAddress(vm VirtualMachine, powerState string) string {
+	if powerState != powerStateOn {
+		return ""
+	}
+
+	ip := hash(fmt.Sprint(vm.Id))
+	return fmt.Sprintf("10.%d.%d.%d", byte(ip>>16), byte(ip>>8), byte(ip)%250+2)
+}
+
+func dailyCost(vm VirtualMachine, disks []Disk) float64 {
+	cost := float64(vm.Cores)*costPerCoreDaily + float64(memoryMb(vm))/1024*costPerMemoryGbDaily
+	for _, disk := range disks {
+		cost += float64(disk.Capacity) * costPerDiskGbDaily[disk.StorageProfile]
+	}
+	return cost
+}
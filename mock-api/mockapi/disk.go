@@ -0,0 +1,153 @@
+package mockapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type addDiskPayload struct {
+	VirtualResourceId string `json:"virtualResourceId"`
+	Tier              string `json:"tier"`
+	Size              int    `json:"size"`
+}
+
+type extendDiskPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	DiskUUID          string `json:"diskUUID"`
+	NewSize           int    `json:"newSize"`
+}
+
+type deleteDiskPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	DiskUUID          string `json:"diskUUID"`
+}
+
+func (s *Server) addDiskHandler(w http.ResponseWriter, r *http.Request) {
+	var payload addDiskPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	if payload.Size <= 0 {
+		handleError(w, "Disk size must be greater than zero", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := tierNames[payload.Tier]; !ok {
+		handleError(w, fmt.Sprintf("Unknown storage profile: %s", payload.Tier), http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	vm.AdditionalDisks = append(vm.AdditionalDisks, Disk{
+		Capacity:       payload.Size,
+		StorageProfile: payload.Tier,
+		MoRef:          uuid.New().String(),
+	})
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) extendDiskHandler(w http.ResponseWriter, r *http.Request) {
+	var payload extendDiskPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	disk := findDisk(&vm, payload.DiskUUID)
+	if disk == nil {
+		handleError(w, "Disk not found", http.StatusNotFound)
+		return
+	}
+
+	// vSphere can only grow disks.
+	if payload.NewSize <= disk.Capacity {
+		handleError(w, fmt.Sprintf("New size %d must be larger than the current size %d", payload.NewSize, disk.Capacity), http.StatusBadRequest)
+		return
+	}
+	disk.Capacity = payload.NewSize
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteDiskHandler(w http.ResponseWriter, r *http.Request) {
+	var payload deleteDiskPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	for i, disk := range vm.AdditionalDisks {
+		if disk.MoRef == payload.DiskUUID {
+			vm.AdditionalDisks = append(vm.AdditionalDisks[:i], vm.AdditionalDisks[i+1:]...)
+
+			if err := s.store.save(vm); err != nil {
+				handleError(w, "Error saving VM details", http.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	if vm.OperatingSystemDisk.MoRef == payload.DiskUUID {
+		handleError(w, "The operating system disk can't be deleted", http.StatusBadRequest)
+		return
+	}
+
+	handleError(w, "Disk not found", http.StatusNotFound)
+}
+
+// findDisk returns the disk with the given MoRef, including the operating
+// system disk, or nil if the VM has no such disk.
+func findDisk(vm *VirtualMachine, moRef string) *Disk {
+	if vm.OperatingSystemDisk.MoRef == moRef {
+		return &vm.OperatingSystemDisk
+	}
+
+	for i := range vm.AdditionalDisks {
+		if vm.AdditionalDisks[i].MoRef == moRef {
+			return &vm.AdditionalDisks[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration written as a string such as "1.5s" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// FaultRule makes matching requests fail with Status, or just slows them
+// down by Latency if Status is zero.
+type FaultRule struct {
+	// Method matches any method if empty.
+	Method string `json:"method,omitempty"`
+	// Path is matched case-insensitively as a prefix of the request path.
+	Path   string `json:"path"`
+	Status int    `json:"status,omitempty"`
+	// Count is how many matching requests are affected. Zero affects every
+	// matching request.
+	Count   int      `json:"count,omitempty"`
+	Latency Duration `json:"latency,omitempty"`
+}
+
+// Faults configures how the mock misbehaves.
+type Faults struct {
+	// Latency is added to every API request.
+	Latency Duration `json:"latency,omitempty"`
+	// ListDelay hides new VMs from the virtual resource list for this long
+	// after they are provisioned.
+	ListDelay Duration `json:"listDelay,omitempty"`
+	// DetailedDelay makes the detailed endpoint return 404 for this long
+	// after a VM is provisioned.
+	DetailedDelay Duration `json:"detailedDelay,omitempty"`
+	// DropDisks leaves additional disks out of the next DropDisks detailed
+	// responses.
+	DropDisks int `json:"dropDisks,omitempty"`
+	// TaskDuration is how long tasks report Running before they complete.
+	TaskDuration Duration    `json:"taskDuration,omitempty"`
+	Rules        []FaultRule `json:"rules,omitempty"`
+}
+
+// ParseFaultRule parses a rule written as [<method> ]<path>=<status>[x<count>],
+// e.g. "POST /api/virtualresource/AddDisk=500x2".
+func ParseFaultRule(s string) (FaultRule, error) {
+	route, result, ok := strings.Cut(s, "=")
+	if !ok {
+		return FaultRule{}, fmt.Errorf("invalid fault %q, expected [<method> ]<path>=<status>[x<count>]", s)
+	}
+
+	var rule FaultRule
+	route = strings.TrimSpace(route)
+	if method, path, ok := strings.Cut(route, " "); ok {
+		rule.Method, rule.Path = strings.ToUpper(method), strings.TrimSpace(path)
+	} else {
+		rule.Path = route
+	}
+
+	status, count, hasCount := strings.Cut(result, "x")
+	var err error
+	if rule.Status, err = strconv.Atoi(status); err != nil {
+		return FaultRule{}, fmt.Errorf("invalid status in fault %q: %w", s, err)
+	}
+	if hasCount {
+		if rule.Count, err = strconv.Atoi(count); err != nil {
+			return FaultRule{}, fmt.Errorf("invalid count in fault %q: %w", s, err)
+		}
+	}
+
+	return rule, nil
+}
+
+func (rule FaultRule) matches(r *http.Request) bool {
+	if rule.Method != "" && !strings.EqualFold(rule.Method, r.Method) {
+		return false
+	}
+	return strings.HasPrefix(strings.ToLower(r.URL.Path), strings.ToLower(rule.Path))
+}
+
+// setFaults replaces the fault configuration.
+func (s *Server) setFaults(faults Faults) {
+	faults.Rules = append([]FaultRule(nil), faults.Rules...)
+
+	s.faultMutex.Lock()
+	s.faults = faults
+	s.faultMutex.Unlock()
+}
+
+func (s *Server) currentFaults() Faults {
+	s.faultMutex.Lock()
+	defer s.faultMutex.Unlock()
+
+	faults := s.faults
+	faults.Rules = append([]FaultRule(nil), s.faults.Rules...)
+	return faults
+}
+
+// nextFault returns the latency to add to r and the status to fail it with,
+// if any, using up one of the matching rule's count.
+func (s *Server) nextFault(r *http.Request) (time.Duration, int) {
+	s.faultMutex.Lock()
+	defer s.faultMutex.Unlock()
+
+	latency := time.Duration(s.faults.Latency)
+	for i := range s.faults.Rules {
+		rule := s.faults.Rules[i]
+		if !rule.matches(r) {
+			continue
+		}
+
+		if rule.Count == 1 {
+			s.faults.Rules = append(s.faults.Rules[:i], s.faults.Rules[i+1:]...)
+		} else if rule.Count > 1 {
+			s.faults.Rules[i].Count--
+		}
+		return latency + time.Duration(rule.Latency), rule.Status
+	}
+
+	return latency, 0
+}
+
+// takeDropDisks reports whether the next detailed response should leave out
+// additional disks.
+func (s *Server) takeDropDisks() bool {
+	s.faultMutex.Lock()
+	defer s.faultMutex.Unlock()
+
+	if s.faults.DropDisks <= 0 {
+		return false
+	}
+	s.faults.DropDisks--
+	return true
+}
+
+// injectFaults applies latency and fault rules before API requests reach
+// next.
+func (s *Server) injectFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/_mock/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		latency, status := s.nextFault(r)
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if status != 0 {
+			log.Printf("Injecting %d for %s %s", status, r.Method, r.URL.Path)
+			if status == http.StatusUnauthorized || status == http.StatusForbidden {
+				writeProblem(w, status, "")
+			} else {
+				http.Error(w, "Injected fault", status)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) faultsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var faults Faults
+		if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+			handleError(w, fmt.Sprintf("Error parsing faults %s", err), http.StatusBadRequest)
+			return
+		}
+		s.setFaults(faults)
+	case http.MethodDelete:
+		s.setFaults(Faults{})
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.currentFaults())
+}
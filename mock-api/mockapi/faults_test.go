@@ -0,0 +1,121 @@
+package mockapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFaultRule(t *testing.T) {
+	tests := []struct {
+		s    string
+		want FaultRule
+	}{
+		{s: "/api/virtualresource/AddDisk=500", want: FaultRule{Path: "/api/virtualresource/AddDisk", Status: 500}},
+		{s: "post /api/virtualresource/AddDisk=503x2", want: FaultRule{Method: "POST", Path: "/api/virtualresource/AddDisk", Status: 503, Count: 2}},
+	}
+
+	for _, tt := range tests {
+		// When
+		rule, err := ParseFaultRule(tt.s)
+
+		// Then
+		if err != nil {
+			t.Errorf("ParseFaultRule(%q) error = %v", tt.s, err)
+		} else if !reflect.DeepEqual(rule, tt.want) {
+			t.Errorf("ParseFaultRule(%q) = %+v, want %+v", tt.s, rule, tt.want)
+		}
+	}
+}
+
+func TestParseFaultRule_Invalid(t *testing.T) {
+	for _, s := range []string{"/api/virtualresource/AddDisk", "/api/virtualresource/AddDisk=abc", "/api/virtualresource/AddDisk=500xabc"} {
+		// When
+		_, err := ParseFaultRule(s)
+
+		// Then
+		if err == nil {
+			t.Errorf("ParseFaultRule(%q) error = nil, want an error", s)
+		}
+	}
+}
+
+func TestFaultRule_FailsCountRequests(t *testing.T) {
+	// Given
+	rule := FaultRule{Method: http.MethodGet, Path: "/api/client/virtualresources/", Status: http.StatusServiceUnavailable, Count: 2}
+	_, ts := newTestServer(t, Options{Faults: Faults{Rules: []FaultRule{rule}}})
+
+	// When
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		statuses = append(statuses, do(t, http.MethodGet, ts.URL+"/api/client/virtualresources/599", nil).StatusCode)
+	}
+
+	// Then
+	want := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+}
+
+func TestFaultRule_AuthFaultsAreProblems(t *testing.T) {
+	// Given
+	rule := FaultRule{Path: "/api/", Status: http.StatusUnauthorized}
+	_, ts := newTestServer(t, Options{Faults: Faults{Rules: []FaultRule{rule}}})
+
+	// When
+	resp := do(t, http.MethodGet, ts.URL+"/api/client/virtualresources/599", nil)
+
+	// Then
+	expectStatus(t, resp, http.StatusUnauthorized)
+	var body problem
+	decode(t, resp, &body)
+	if body.Status != http.StatusUnauthorized {
+		t.Errorf("problem status = %d, want %d", body.Status, http.StatusUnauthorized)
+	}
+}
+
+func TestFaultRule_IgnoresMockEndpoints(t *testing.T) {
+	// Given
+	rule := FaultRule{Path: "/", Status: http.StatusInternalServerError}
+	_, ts := newTestServer(t, Options{Faults: Faults{Rules: []FaultRule{rule}}})
+
+	// When
+	resp := send(t, newRequest(t, http.MethodGet, ts.URL+"/_mock/state", nil))
+
+	// Then
+	expectStatus(t, resp, http.StatusOK)
+}
+
+func TestFaultsHandler(t *testing.T) {
+	// Given
+	_, ts := newTestServer(t, Options{})
+	faults := Faults{
+		Latency: Duration(10 * time.Millisecond),
+		Rules:   []FaultRule{{Path: "/api/client/virtualresources/", Status: http.StatusInternalServerError, Count: 1}},
+	}
+
+	// When
+	resp := send(t, newRequest(t, http.MethodPost, ts.URL+"/_mock/faults", faults))
+
+	// Then
+	expectStatus(t, resp, http.StatusOK)
+	var got Faults
+	decode(t, resp, &got)
+	if !reflect.DeepEqual(got, faults) {
+		t.Errorf("faults = %+v, want %+v", got, faults)
+	}
+	expectStatus(t, do(t, http.MethodGet, ts.URL+"/api/client/virtualresources/599", nil), http.StatusInternalServerError)
+
+	// When
+	resp = send(t, newRequest(t, http.MethodDelete, ts.URL+"/_mock/faults", nil))
+
+	// Then
+	expectStatus(t, resp, http.StatusOK)
+	got = Faults{}
+	decode(t, resp, &got)
+	if !reflect.DeepEqual(got, Faults{}) {
+		t.Errorf("faults after DELETE = %+v, want none", got)
+	}
+}
@@ -0,0 +1,50 @@
+package mockapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type changeGuestOSPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	GuestOsId         string `json:"guestOsId"`
+}
+
+// changeGuestOSHandler sets a VM's guest OS. As in vSphere, the VM has to be
+// powered off first.
+func (s *Server) changeGuestOSHandler(w http.ResponseWriter, r *http.Request) {
+	var payload changeGuestOSPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	if _, ok := guestOSNames[payload.GuestOsId]; !ok {
+		handleError(w, fmt.Sprintf("Unknown guest OS: %s", payload.GuestOsId), http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	if vm.PowerState != powerStateOff {
+		handleError(w, "VM must be powered off to change its guest OS", http.StatusConflict)
+		return
+	}
+
+	log.Printf("Changing guest OS of VM %d: %s -> %s", vm.Id, vm.GuestOsId, payload.GuestOsId)
+	vm.GuestOsId = payload.GuestOsId
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
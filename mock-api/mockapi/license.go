@@ -0,0 +1,104 @@
+package mockapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type assignLicensePayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Name              string `json:"name"`
+}
+
+type removeLicensePayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	LicenseId         int    `json:"licenseId"`
+}
+
+func isWindows(vm VirtualMachine) bool {
+	return strings.HasPrefix(strings.ToLower(vm.GuestOsId), "windows")
+}
+
+// assignedLicense returns the ID and key of the license a VM consumes,
+// derived from the VM so they stay the same between requests.
+func assignedLicense(vm VirtualMachine) (int, string) {
+	seed := fmt.Sprintf("%d/%s", vm.Id, vm.License)
+
+	var groups []string
+	for i := 0; i < 5; i++ {
+		groups = append(groups, fmt.Sprintf("%05X", hash(fmt.Sprintf("%s/%d", seed, i))&0xFFFFF))
+	}
+	return int(hash(seed)%90000) + 10000, strings.Join(groups, "-")
+}
+
+func (s *Server) assignLicenseHandler(w http.ResponseWriter, r *http.Request) {
+	var payload assignLicensePayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	if !isWindows(vm) {
+		handleError(w, "Licenses can only be assigned to Windows VMs", http.StatusBadRequest)
+		return
+	}
+
+	known := false
+	for _, name := range windowsLicenses {
+		known = known || name == payload.Name
+	}
+	if !known {
+		handleError(w, fmt.Sprintf("Unknown license: %s", payload.Name), http.StatusBadRequest)
+		return
+	}
+
+	vm.License = payload.Name
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Assigned license %s to VM %d", vm.License, vm.Id)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) removeLicenseHandler(w http.ResponseWriter, r *http.Request) {
+	var payload removeLicensePayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	if licenseId, _ := assignedLicense(vm); vm.License == "" || licenseId != payload.LicenseId {
+		handleError(w, "License not assigned to VM", http.StatusNotFound)
+		return
+	}
+
+	vm.License = ""
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,67 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+)
+
+// hostingLocation is a site VMs can be provisioned at or migrated to, with
+// the memory sizes it accepts.
+type hostingLocation struct {
+	Name              string
+	MinMemoryMb       int
+	MaxMemoryMb       int
+	MemoryIncrementMb int
+}
+
+// hostingLocations are the known sites, by ID.
+var hostingLocations = map[string]hostingLocation{
+	"vcchcres": {Name: "Christchurch", MinMemoryMb: 512, MaxMemoryMb: 1048576, MemoryIncrementMb: 256},
+	"vcaklres": {Name: "Auckland", MinMemoryMb: 1024, MaxMemoryMb: 524288, MemoryIncrementMb: 512},
+}
+
+// checkMemory returns why memoryMb isn't allowed at a hosting location, or
+// "" if it is. Unknown locations accept any size.
+func checkMemory(hostingLocationID string, memoryMb int) string {
+	location, ok := hostingLocations[hostingLocationID]
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case memoryMb < location.MinMemoryMb || memoryMb > location.MaxMemoryMb:
+		return fmt.Sprintf("Memory (%d MB) must be between %d and %d MB at %s", memoryMb, location.MinMemoryMb, location.MaxMemoryMb, hostingLocationID)
+	case memoryMb%location.MemoryIncrementMb != 0:
+		return fmt.Sprintf("Memory (%d MB) must be a multiple of %d MB at %s", memoryMb, location.MemoryIncrementMb, hostingLocationID)
+	}
+	return ""
+}
+
+func (s *Server) hostingLocationLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	id := path.Base(r.URL.Path)
+	location, ok := hostingLocations[id]
+	if !ok {
+		handleError(w, "Hosting location not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"hostingLocationId": id,
+		"name":              location.Name,
+		"minMemoryMb":       location.MinMemoryMb,
+		"maxMemoryMb":       location.MaxMemoryMb,
+		"memoryIncrementMb": location.MemoryIncrementMb,
+	})
+}
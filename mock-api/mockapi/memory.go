@@ -0,0 +1,70 @@
+package mockapi
+
+import (
+	"log"
+	"net/http"
+)
+
+type updateMemoryPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	MemoryMb          int    `json:"memoryMb"`
+}
+
+// memoryMb returns a VM's memory in MB. VMs provisioned with a whole number
+// of GB only have MemorySize set.
+func memoryMb(vm VirtualMachine) int {
+	if vm.MemoryMb > 0 {
+		return vm.MemoryMb
+	}
+	return vm.MemorySize * 1024
+}
+
+// setMemory sets a VM's memory, keeping MemorySize in whole GB for the list
+// endpoint.
+func setMemory(vm *VirtualMachine, memoryMb int) {
+	vm.MemoryMb = memoryMb
+	vm.MemorySize = memoryMb / 1024
+}
+
+// updateMemoryHandler changes a VM's memory. As in vSphere, memory can be
+// hot added to a running VM but not removed.
+func (s *Server) updateMemoryHandler(w http.ResponseWriter, r *http.Request) {
+	var payload updateMemoryPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	if payload.MemoryMb <= 0 {
+		handleError(w, "Memory must be positive", http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	if msg := checkMemory(vm.HostingLocation.Id, payload.MemoryMb); msg != "" {
+		handleError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	if vm.PowerState != powerStateOff && payload.MemoryMb < memoryMb(vm) {
+		handleError(w, "VM must be powered off to remove memory", http.StatusConflict)
+		return
+	}
+
+	log.Printf("Updating memory of VM %d: %d MB -> %d MB", vm.Id, memoryMb(vm), payload.MemoryMb)
+	setMemory(&vm, payload.MemoryMb)
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,67 @@
+package mockapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type migratePayload struct {
+	VirtualResourceId string            `json:"VirtualResourceId"`
+	HostingLocationId string            `json:"hostingLocationId"`
+	NetworkMapping    map[string]string `json:"networkMapping"`
+}
+
+// migrateHandler starts a task moving a VM to another hosting location. The
+// VM's network has to be mapped to one at the target.
+func (s *Server) migrateHandler(w http.ResponseWriter, r *http.Request) {
+	var payload migratePayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	location, ok := hostingLocations[payload.HostingLocationId]
+	if !ok {
+		handleError(w, fmt.Sprintf("Unknown hosting location: %s", payload.HostingLocationId), http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	if vm.HostingLocation.Id == payload.HostingLocationId {
+		handleError(w, fmt.Sprintf("VM is already at %s", payload.HostingLocationId), http.StatusConflict)
+		return
+	}
+
+	if msg := checkMemory(payload.HostingLocationId, memoryMb(vm)); msg != "" {
+		handleError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	network, ok := payload.NetworkMapping[vm.HostingLocation.DefaultNetwork]
+	if !ok || network == "" {
+		handleError(w, fmt.Sprintf("No target network for %s", vm.HostingLocation.DefaultNetwork), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Migrating VM %d: %s -> %s", vm.Id, vm.HostingLocation.Id, payload.HostingLocationId)
+	vm.HostingLocation = Location{
+		Id:             payload.HostingLocationId,
+		Name:           location.Name,
+		DefaultNetwork: network,
+	}
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	writeTask(w, s.startTask(vm.Id))
+}
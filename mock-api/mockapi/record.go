@@ -0,0 +1,262 @@
+package mockapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Recorder proxies requests to a real vBridge API and writes each
+// interaction to a cassette, with credentials and identifiers replaced.
+type Recorder struct {
+	proxy    *httputil.ReverseProxy
+	fileName string
+
+	mu        sync.Mutex
+	cassette  Cassette
+	sanitizer *sanitizer
+}
+
+// NewRecorder returns a Recorder forwarding to target and writing to
+// fileName. Any strings in redact are removed from recorded bodies, as are
+// the API key and user sent with each request.
+func NewRecorder(target *url.URL, fileName string, redact ...string) *Recorder {
+	rec := &Recorder{
+		proxy:     httputil.NewSingleHostReverseProxy(target),
+		fileName:  fileName,
+		sanitizer: newSanitizer(redact),
+	}
+
+	director := rec.proxy.Director
+	rec.proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = target.Host
+	}
+	rec.proxy.ModifyResponse = rec.record
+
+	return rec
+}
+
+// Handler returns the recording proxy.
+func (rec *Recorder) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, "Error reading request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keep the original body for the recording, as the proxy consumes
+		// the request's.
+		r = r.WithContext(context.WithValue(r.Context(), recordedBodyKey{}, body))
+
+		rec.proxy.ServeHTTP(w, r)
+	})
+}
+
+// record is the proxy's ModifyResponse hook. It reads the response body,
+// restores it for the client and appends the sanitised interaction.
+func (rec *Recorder) record(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r := resp.Request
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.sanitizer.redactCredentials(r.Header)
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: r.Method,
+			Path:   rec.sanitizer.path(r.URL.Path),
+			Body:   rec.sanitizer.json(recordedBody(r.Context())),
+		},
+		Response: RecordedResponse{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+		},
+	}
+
+	if sanitized := rec.sanitizer.json(body); sanitized != nil {
+		interaction.Response.Body = sanitized
+	} else {
+		interaction.Response.Text = rec.sanitizer.text(string(body))
+	}
+
+	rec.cassette.Interactions = append(rec.cassette.Interactions, interaction)
+	log.Printf("Recorded %s %s -> %d", r.Method, interaction.Request.Path, resp.StatusCode)
+
+	// Save after every interaction so nothing is lost if the recorder is
+	// stopped with Ctrl+C.
+	return rec.cassette.save(rec.fileName)
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	macPattern   = regexp.MustCompile(`^([0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}$`)
+	ipPattern    = regexp.MustCompile(`^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}$`)
+)
+
+// idKeys are JSON keys whose numeric values identify a client or resource.
+var idKeys = map[string]bool{
+	"id":                true,
+	"clientid":          true,
+	"virtualresourceid": true,
+	"licenseid":         true,
+}
+
+// sanitizer replaces credentials and identifiers in recorded traffic. Each
+// real identifier is mapped to the same fake one everywhere it appears, so
+// paths and bodies in a cassette still refer to each other.
+type sanitizer struct {
+	redact []string
+	ids    map[string]string
+	nextID int
+}
+
+func newSanitizer(redact []string) *sanitizer {
+	return &sanitizer{redact: redact, ids: make(map[string]string), nextID: 1000}
+}
+
+func (s *sanitizer) redactCredentials(header http.Header) {
+	if key, ok := strings.CutPrefix(header.Get("Authorization"), "apiKey "); ok {
+		s.addRedaction(key)
+	}
+	s.addRedaction(header.Get("x-mcs-user"))
+}
+
+func (s *sanitizer) addRedaction(value string) {
+	if value == "" {
+		return
+	}
+	for _, existing := range s.redact {
+		if existing == value {
+			return
+		}
+	}
+	s.redact = append(s.redact, value)
+}
+
+// id returns the fake identifier for a real one.
+func (s *sanitizer) id(real string) string {
+	if real == "" || real == "0" {
+		return real
+	}
+
+	fake, ok := s.ids[real]
+	if !ok {
+		fake = strconv.Itoa(s.nextID)
+		s.nextID++
+		s.ids[real] = fake
+	}
+	return fake
+}
+
+// path replaces numeric path segments, which are always client or resource
+// IDs in the vBridge API.
+func (s *sanitizer) path(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if isNumeric(segment) {
+			segments[i] = s.id(segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+func (s *sanitizer) text(value string) string {
+	for _, secret := range s.redact {
+		value = strings.ReplaceAll(value, secret, "REDACTED")
+	}
+	return emailPattern.ReplaceAllString(value, "user@example.com")
+}
+
+// json returns a sanitised copy of a JSON body, or nil if body is empty or
+// isn't JSON. Numbers are kept as written, so a capacity of 100.0 stays a
+// float.
+func (s *sanitizer) json(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+
+	sanitized, err := json.Marshal(s.value("", value))
+	if err != nil {
+		return nil
+	}
+	return sanitized
+}
+
+func (s *sanitizer) value(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = s.value(k, child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = s.value(key, child)
+		}
+		return v
+	case json.Number:
+		if idKeys[strings.ToLower(key)] {
+			return json.Number(s.id(v.String()))
+		}
+		return v
+	case string:
+		return s.string(key, v)
+	default:
+		return v
+	}
+}
+
+func (s *sanitizer) string(key, value string) string {
+	switch {
+	case idKeys[strings.ToLower(key)] && isNumeric(value):
+		return s.id(value)
+	case strings.EqualFold(key, "licenseKey") && value != "":
+		return "XXXXX-XXXXX-XXXXX-XXXXX-XXXXX"
+	case strings.EqualFold(key, "moRef") && strings.HasPrefix(value, "vm-"):
+		return "vm-" + s.id(strings.TrimPrefix(value, "vm-"))
+	case macPattern.MatchString(value):
+		return "00:50:56:00:00:00"
+	case ipPattern.MatchString(value):
+		return "192.0.2.1"
+	}
+	return s.text(value)
+}
+
+type recordedBodyKey struct{}
+
+func recordedBody(ctx context.Context) []byte {
+	body, _ := ctx.Value(recordedBodyKey{}).([]byte)
+	return body
+}
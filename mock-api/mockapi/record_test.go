@@ -0,0 +1,80 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder_SanitisesInteractions(t *testing.T) {
+	// Given
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 7452, "clientId": 12345, "moRef": "vm-7452", "owner": "real@customer.co.nz", "contact": "ops@customer.co.nz",
+			"macAddress": "00:50:56:ab:cd:ef", "ipAddress": "10.1.2.3", "note": "secret-project", "capacity": 100.0}`))
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL)
+	cassetteFile := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := httptest.NewServer(NewRecorder(target, cassetteFile, "secret-project").Handler())
+	defer recorder.Close()
+
+	req := newRequest(t, http.MethodGet, recorder.URL+"/api/VirtualResource/Detailed/7452", nil)
+	req.Header.Set("Authorization", "apiKey realkey")
+	req.Header.Set("x-mcs-user", "real@customer.co.nz")
+
+	// When
+	resp := send(t, req)
+
+	// Then
+	expectStatus(t, resp, http.StatusOK)
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "7452") {
+		t.Errorf("proxied body = %s, want the upstream response", body)
+	}
+
+	cassette, err := LoadCassette(cassetteFile)
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+	if len(cassette.Interactions) != 1 {
+		t.Fatalf("recorded %d interactions, want 1", len(cassette.Interactions))
+	}
+
+	interaction := cassette.Interactions[0]
+	if interaction.Request.Path != "/api/VirtualResource/Detailed/1000" {
+		t.Errorf("recorded path = %s, want the VM ID replaced", interaction.Request.Path)
+	}
+
+	var recorded map[string]interface{}
+	if err := json.Unmarshal(interaction.Response.Body, &recorded); err != nil {
+		t.Fatalf("recorded body isn't JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"id":         1000.0,
+		"clientId":   1001.0,
+		"moRef":      "vm-1000",
+		"owner":      "REDACTED",
+		"contact":    "user@example.com",
+		"macAddress": "00:50:56:00:00:00",
+		"ipAddress":  "192.0.2.1",
+		"note":       "REDACTED",
+		"capacity":   100.0,
+	}
+	for key, value := range want {
+		if recorded[key] != value {
+			t.Errorf("recorded %s = %v, want %v", key, recorded[key], value)
+		}
+	}
+	for _, secret := range []string{"realkey", "customer.co.nz", "7452", "12345"} {
+		if strings.Contains(string(interaction.Response.Body), secret) {
+			t.Errorf("recorded body %s contains %q", interaction.Response.Body, secret)
+		}
+	}
+}
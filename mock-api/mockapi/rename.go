@@ -0,0 +1,56 @@
+package mockapi
+
+import (
+	"log"
+	"net/http"
+)
+
+type renamePayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Name              string `json:"name"`
+}
+
+// renameHandler renames a VM in place. Names must be unique within a client,
+// matching what the portal enforces.
+func (s *Server) renameHandler(w http.ResponseWriter, r *http.Request) {
+	var payload renamePayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	if payload.Name == "" {
+		handleError(w, "VM name is required", http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	allVMs, err := s.store.list()
+	if err != nil {
+		handleError(w, "Error loading VMs", http.StatusInternalServerError)
+		return
+	}
+	for _, other := range allVMs {
+		if other.ClientId == vm.ClientId && other.Id != vm.Id && other.Name == payload.Name {
+			handleError(w, "A VM with that name already exists", http.StatusConflict)
+			return
+		}
+	}
+
+	log.Printf("Renaming VM %d: %s -> %s", vm.Id, vm.Name, payload.Name)
+	vm.Name = payload.Name
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
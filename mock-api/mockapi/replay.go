@@ -0,0 +1,71 @@
+package mockapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Replayer serves the responses in a cassette. Requests are matched on method
+// and path; if the same request was recorded more than once its responses
+// are returned in order, with the last one repeated.
+type Replayer struct {
+	mu        sync.Mutex
+	responses map[string][]RecordedResponse
+}
+
+func NewReplayer(cassette Cassette) *Replayer {
+	replayer := &Replayer{responses: make(map[string][]RecordedResponse)}
+	for _, interaction := range cassette.Interactions {
+		key := replayKey(interaction.Request.Method, interaction.Request.Path)
+		replayer.responses[key] = append(replayer.responses[key], interaction.Response)
+	}
+	return replayer
+}
+
+func replayKey(method, path string) string {
+	return strings.ToUpper(method) + " " + strings.ToLower(path)
+}
+
+// next returns the response to replay for a request, if one was recorded.
+func (rp *Replayer) next(method, path string) (RecordedResponse, bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	key := replayKey(method, path)
+	responses := rp.responses[key]
+	if len(responses) == 0 {
+		return RecordedResponse{}, false
+	}
+
+	if len(responses) > 1 {
+		rp.responses[key] = responses[1:]
+	}
+	return responses[0], true
+}
+
+// Handler returns the replaying server. Recorded credentials are redacted, so
+// requests aren't authenticated.
+func (rp *Replayer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := rp.next(r.Method, r.URL.Path)
+		if !ok {
+			handleError(w, fmt.Sprintf("No recorded interaction for %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+			return
+		}
+
+		log.Printf("Replaying %s %s -> %d", r.Method, r.URL.Path, response.Status)
+		if response.ContentType != "" {
+			w.Header().Set("Content-Type", response.ContentType)
+		}
+		w.WriteHeader(response.Status)
+
+		if response.Body != nil {
+			w.Write(response.Body)
+		} else {
+			w.Write([]byte(response.Text))
+		}
+	})
+}
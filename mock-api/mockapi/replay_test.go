@@ -0,0 +1,57 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReplayer_ReturnsResponsesInOrder(t *testing.T) {
+	// Given
+	cassette := Cassette{Interactions: []Interaction{
+		{
+			Request:  RecordedRequest{Method: http.MethodGet, Path: "/api/VirtualResource/Detailed/1000"},
+			Response: RecordedResponse{Status: http.StatusNotFound, Text: "not yet"},
+		},
+		{
+			Request:  RecordedRequest{Method: http.MethodGet, Path: "/api/VirtualResource/Detailed/1000"},
+			Response: RecordedResponse{Status: http.StatusOK, ContentType: "application/json", Body: json.RawMessage(`{"id":1000}`)},
+		},
+	}}
+	ts := httptest.NewServer(NewReplayer(cassette).Handler())
+	defer ts.Close()
+
+	// When
+	var statuses []int
+	var bodies []string
+	for i := 0; i < 3; i++ {
+		// Paths are matched case-insensitively, as the API does.
+		resp := send(t, newRequest(t, http.MethodGet, ts.URL+"/api/virtualresource/detailed/1000", nil))
+		body, _ := io.ReadAll(resp.Body)
+		statuses = append(statuses, resp.StatusCode)
+		bodies = append(bodies, string(body))
+	}
+
+	// Then
+	wantStatuses := []int{http.StatusNotFound, http.StatusOK, http.StatusOK}
+	wantBodies := []string{"not yet", `{"id":1000}`, `{"id":1000}`}
+	for i := range wantStatuses {
+		if statuses[i] != wantStatuses[i] || bodies[i] != wantBodies[i] {
+			t.Errorf("response %d = %d %s, want %d %s", i, statuses[i], bodies[i], wantStatuses[i], wantBodies[i])
+		}
+	}
+}
+
+func TestReplayer_UnrecordedRequest(t *testing.T) {
+	// Given
+	ts := httptest.NewServer(NewReplayer(Cassette{}).Handler())
+	defer ts.Close()
+
+	// When
+	resp := send(t, newRequest(t, http.MethodPost, ts.URL+"/api/virtualresource/delete", nil))
+
+	// Then
+	expectStatus(t, resp, http.StatusNotFound)
+}
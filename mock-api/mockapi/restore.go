@@ -0,0 +1,214 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"time"
+)
+
+// restorePointRetention is how many daily restore points are kept.
+const restorePointRetention = 7
+
+const (
+	restoreTypeFull            = "Full"
+	restoreTypeInstantRecovery = "InstantRecovery"
+)
+
+// InstantRecoveryDisk is a disk mounted on a VM from a restore point.
+type InstantRecoveryDisk struct {
+	Disk
+	Name           string `json:"name"`
+	RestorePointId string `json:"restorePointId"`
+}
+
+type restorePoint struct {
+	Id        string    `json:"id"`
+	CreatedOn time.Time `json:"createdOn"`
+	JobName   string    `json:"jobName"`
+	Type      string    `json:"type"`
+}
+
+type restorePayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	RestorePointId    string `json:"restorePointId"`
+	RestoreType       string `json:"restoreType"`
+}
+
+type unmountInstantRecoveryPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	RestorePointId    string `json:"restorePointId"`
+}
+
+// generateRestorePoints returns a week of nightly restore points, oldest
+// first, for VMs that are backed up. The mock pretends every backed up VM
+// has been around long enough to fill its retention.
+func generateRestorePoints(vm VirtualMachine) []restorePoint {
+	if vm.BackupType == "" || vm.BackupType == "vBackupNone" {
+		return []restorePoint{}
+	}
+
+	// Backups run at 21:00 NZST.
+	latest := time.Now().UTC().Truncate(24 * time.Hour).Add(9 * time.Hour)
+	if latest.After(time.Now()) {
+		latest = latest.AddDate(0, 0, -1)
+	}
+
+	points := make([]restorePoint, 0, restorePointRetention)
+	for i := restorePointRetention - 1; i >= 0; i-- {
+		createdOn := latest.AddDate(0, 0, -i)
+		pointType := "Increment"
+		if len(points) == 0 {
+			pointType = "Full"
+		}
+
+		points = append(points, restorePoint{
+			Id:        fmt.Sprintf("rp-%d-%s", vm.Id, createdOn.Format("20060102")),
+			CreatedOn: createdOn,
+			JobName:   "DailyAA",
+			Type:      pointType,
+		})
+	}
+	return points
+}
+
+func findRestorePoint(vm VirtualMachine, restorePointID string) (restorePoint, bool) {
+	for _, point := range generateRestorePoints(vm) {
+		if point.Id == restorePointID {
+			return point, true
+		}
+	}
+	return restorePoint{}, false
+}
+
+func generateInstantRecoveryDisks(vm VirtualMachine) []map[string]interface{} {
+	disks := []map[string]interface{}{}
+	for _, disk := range vm.InstantRecoveryDisks {
+		disks = append(disks, map[string]interface{}{
+			"name":           disk.Name,
+			"moRef":          disk.MoRef,
+			"capacity":       decimal(disk.Capacity),
+			"tier":           tierNames[disk.StorageProfile],
+			"restorePointId": disk.RestorePointId,
+		})
+	}
+	return disks
+}
+
+func (s *Server) listRestorePointsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenant, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	vm, ok := s.loadTenantVM(w, tenant, path.Base(r.URL.Path))
+	s.vmMutex.Unlock()
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(generateRestorePoints(vm))
+}
+
+// restoreHandler starts a restore task. A full restore leaves the VM
+// powered off with the disks it had; an instant recovery mounts a copy of
+// each disk from the restore point.
+func (s *Server) restoreHandler(w http.ResponseWriter, r *http.Request) {
+	var payload restorePayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	if payload.RestoreType != restoreTypeFull && payload.RestoreType != restoreTypeInstantRecovery {
+		handleError(w, fmt.Sprintf("Unknown restore type: %s", payload.RestoreType), http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	if _, ok := findRestorePoint(vm, payload.RestorePointId); !ok {
+		handleError(w, "Restore point not found", http.StatusNotFound)
+		return
+	}
+
+	switch payload.RestoreType {
+	case restoreTypeFull:
+		vm.PowerState = powerStateOff
+	case restoreTypeInstantRecovery:
+		for _, disk := range vm.InstantRecoveryDisks {
+			if disk.RestorePointId == payload.RestorePointId {
+				handleError(w, "Restore point is already mounted", http.StatusConflict)
+				return
+			}
+		}
+
+		disks := append([]Disk{vm.OperatingSystemDisk}, vm.AdditionalDisks...)
+		for i, disk := range disks {
+			vm.InstantRecoveryDisks = append(vm.InstantRecoveryDisks, InstantRecoveryDisk{
+				Disk:           Disk{Capacity: disk.Capacity, StorageProfile: disk.StorageProfile, MoRef: fmt.Sprintf("%s-ir-%d", payload.RestorePointId, i)},
+				Name:           fmt.Sprintf("Hard disk %d (Instant Recovery)", i+1),
+				RestorePointId: payload.RestorePointId,
+			})
+		}
+	}
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Started %s restore of VM %d from %s", payload.RestoreType, vm.Id, payload.RestorePointId)
+	writeTask(w, s.startTask(vm.Id))
+}
+
+func (s *Server) unmountInstantRecoveryHandler(w http.ResponseWriter, r *http.Request) {
+	var payload unmountInstantRecoveryPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	var remaining []InstantRecoveryDisk
+	for _, disk := range vm.InstantRecoveryDisks {
+		if disk.RestorePointId != payload.RestorePointId {
+			remaining = append(remaining, disk)
+		}
+	}
+
+	if len(remaining) == len(vm.InstantRecoveryDisks) {
+		handleError(w, "Restore point is not mounted", http.StatusNotFound)
+		return
+	}
+	vm.InstantRecoveryDisks = remaining
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	writeTask(w, s.startTask(vm.Id))
+}
@@ -0,0 +1,153 @@
+// Package mockapi is a stand-in for the vBridge API that can be run as a
+// standalone server or embedded in tests with httptest.
+package mockapi
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+type VirtualMachine struct {
+	ClientId            int        `json:"clientId"`
+	Name                string     `json:"name"`
+	Template            string     `json:"template"`
+	GuestOsId           string     `json:"guestOsId"`
+	Cores               int        `json:"cores"`
+	CoresPerSocket      int        `json:"coresPerSocket,omitempty"`
+	MemorySize          int        `json:"memorySize"`
+	MemoryMb            int        `json:"memoryMb,omitempty"`
+	OperatingSystemDisk Disk       `json:"operatingSystemDisk"`
+	AdditionalDisks     []Disk     `json:"additionalDisks,omitempty"`
+	IsoFile             string     `json:"isoFile,omitempty"`
+	QuoteItem           Quote      `json:"quoteItem,omitempty"`
+	BackupType          string     `json:"backupType"`
+	Id                  int        `json:"id"`
+	HostingLocation     Location   `json:"hostingLocation"`
+	MoRef               string     `json:"moRef"`
+	PowerState          string     `json:"powerState"`
+	Snapshots           []Snapshot `json:"snapshots,omitempty"`
+	// License is the name of the Windows license the VM consumes.
+	License    string `json:"license,omitempty"`
+	Annotation string `json:"annotation,omitempty"`
+	// InstantRecoveryDisks are disks mounted from restore points.
+	InstantRecoveryDisks []InstantRecoveryDisk `json:"instantRecoveryDisks,omitempty"`
+	// ProvisionedAt is used to simulate the delay before a new VM shows up
+	// in the list and detailed endpoints.
+	ProvisionedAt time.Time `json:"provisionedAt,omitempty"`
+}
+
+type Disk struct {
+	Capacity       int    `json:"capacity"`
+	StorageProfile string `json:"storageProfile"`
+	MoRef          string `json:"moRef,omitempty"`
+}
+
+type Quote struct{}
+
+type Location struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	DefaultNetwork string `json:"defaultNetwork"`
+}
+
+// Power states reported in the detailed response's specification.
+const (
+	powerStateOn  = "On"
+	powerStateOff = "Off"
+)
+
+// Options configures a Server.
+type Options struct {
+	// DataDir is where VMs are stored as <id>.json files. If empty, VMs are
+	// only kept in memory.
+	DataDir string
+	// Tenants are the credentials accepted. DefaultTenant is used if empty.
+	Tenants []Tenant
+	// Seed is loaded when the server starts and whenever it is reset.
+	Seed []VirtualMachine
+	// Faults is the initial fault configuration, restored on reset.
+	Faults Faults
+}
+
+// Server holds the mock's state.
+type Server struct {
+	vmMutex  sync.Mutex
+	store    store
+	lastVMID int
+	tenants  []Tenant
+	seed     []VirtualMachine
+
+	faultMutex    sync.Mutex
+	faults        Faults
+	initialFaults Faults
+
+	taskMutex sync.Mutex
+	tasks     map[string]task
+}
+
+// New returns a server configured by opts, loading its seed VMs. Existing
+// VMs in opts.DataDir are kept.
+func New(opts Options) (*Server, error) {
+	s := &Server{tenants: opts.Tenants, seed: opts.Seed, initialFaults: opts.Faults, tasks: make(map[string]task)}
+	s.setFaults(opts.Faults)
+	if len(s.tenants) == 0 {
+		s.tenants = []Tenant{DefaultTenant}
+	}
+
+	if opts.DataDir == "" {
+		s.store = newMemoryStore()
+	} else {
+		if err := os.MkdirAll(opts.DataDir, 0755); err != nil {
+			return nil, err
+		}
+		s.store = fileStore{dataDir: opts.DataDir}
+	}
+
+	if err := s.loadSeed(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Handler returns the routes served by the mock.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/Provisioning/VirtualMachine", s.provisionVMHandler)
+	mux.HandleFunc("/api/client/virtualresources/", s.getAllVMsHandler)
+	mux.HandleFunc("/api/VirtualResource/Detailed/", s.getVMDetailedByIDHandler)
+	mux.HandleFunc("/api/virtualresource/poweroperation", s.powerOperationHandler)
+	mux.HandleFunc("/api/virtualresource/delete", s.deleteVMHandler)
+	mux.HandleFunc("/api/virtualresource/Rename", s.renameHandler)
+	mux.HandleFunc("/api/virtualresource/ChangeGuestOS", s.changeGuestOSHandler)
+	mux.HandleFunc("/api/virtualresource/Migrate", s.migrateHandler)
+	mux.HandleFunc("/api/virtualresource/UpdateCPU", s.updateCPUHandler)
+	mux.HandleFunc("/api/virtualresource/UpdateMemory", s.updateMemoryHandler)
+	mux.HandleFunc("/api/HostingLocation/Limits/", s.hostingLocationLimitsHandler)
+	mux.HandleFunc("/api/virtualresource/AddDisk", s.addDiskHandler)
+	mux.HandleFunc("/api/VirtualResource/ExtendDisk", s.extendDiskHandler)
+	mux.HandleFunc("/api/virtualresource/DeleteDisk", s.deleteDiskHandler)
+	mux.HandleFunc("/api/VirtualResource/Snapshots/", s.listSnapshotsHandler)
+	mux.HandleFunc("/api/virtualresource/CreateSnapshot", s.createSnapshotHandler)
+	mux.HandleFunc("/api/virtualresource/DeleteSnapshot", s.deleteSnapshotHandler)
+	mux.HandleFunc("/api/virtualresource/RevertSnapshot", s.revertSnapshotHandler)
+	mux.HandleFunc("/api/VirtualResource/RestorePoints/", s.listRestorePointsHandler)
+	mux.HandleFunc("/api/virtualresource/Restore", s.restoreHandler)
+	mux.HandleFunc("/api/virtualresource/UnmountInstantRecovery", s.unmountInstantRecoveryHandler)
+	mux.HandleFunc("/api/Task/", s.getTaskHandler)
+	mux.HandleFunc("/api/virtualresource/AssignLicense", s.assignLicenseHandler)
+	mux.HandleFunc("/api/virtualresource/RemoveLicense", s.removeLicenseHandler)
+	mux.HandleFunc("/api/virtualresource/UpdateAnnotation", s.updateAnnotationHandler)
+	mux.HandleFunc("/_mock/reset", s.resetHandler)
+	mux.HandleFunc("/_mock/state", s.stateHandler)
+	mux.HandleFunc("/_mock/faults", s.faultsHandler)
+	return s.injectFaults(mux)
+}
+
+func handleError(w http.ResponseWriter, message string, statusCode int) {
+	log.Println(message)
+	http.Error(w, message, statusCode)
+}
@@ -0,0 +1,174 @@
+package mockapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newTestServer(t *testing.T, opts Options) (*Server, *httptest.Server) {
+	t.Helper()
+
+	server, err := New(opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return server, ts
+}
+
+// do sends a request with DefaultTenant's credentials, encoding body as JSON
+// unless it is nil.
+func do(t *testing.T, method, url string, body interface{}) *http.Response {
+	t.Helper()
+
+	req := newRequest(t, method, url, body)
+	req.Header.Set("Authorization", "apiKey "+DefaultTenant.APIKey)
+	req.Header.Set("x-mcs-user", DefaultTenant.User)
+	return send(t, req)
+}
+
+func newRequest(t *testing.T, method, url string, body interface{}) *http.Request {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("http.NewRequest() error = %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
+func send(t *testing.T, req *http.Request) *http.Response {
+	t.Helper()
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", req.Method, req.URL.Path, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decode(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decoding %s response: %v", resp.Request.URL.Path, err)
+	}
+}
+
+func expectStatus(t *testing.T, resp *http.Response, want int) {
+	t.Helper()
+
+	if resp.StatusCode != want {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("%s %s status = %d, want %d: %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, want, body)
+	}
+}
+
+func testVM(name string) VirtualMachine {
+	return VirtualMachine{
+		ClientId:            599,
+		Name:                name,
+		Template:            "Windows2022_Standard_30GB",
+		GuestOsId:           "windows2019srvNext_64Guest",
+		Cores:               2,
+		MemorySize:          4,
+		OperatingSystemDisk: Disk{Capacity: 30, StorageProfile: "vStorageT1"},
+		BackupType:          "vBackupDisk",
+		HostingLocation:     Location{Id: "vcchcres", Name: "Christchurch", DefaultNetwork: "Default"},
+	}
+}
+
+// provision creates a VM and returns its ID from the mock's state.
+func provision(t *testing.T, url string, vm VirtualMachine) int {
+	t.Helper()
+
+	expectStatus(t, do(t, http.MethodPost, url+"/api/Provisioning/VirtualMachine", vm), http.StatusOK)
+
+	for _, stored := range state(t, url) {
+		if stored.Name == vm.Name {
+			return stored.Id
+		}
+	}
+	t.Fatalf("provisioned VM %s is not in the mock's state", vm.Name)
+	return 0
+}
+
+func state(t *testing.T, url string) []VirtualMachine {
+	t.Helper()
+
+	resp := send(t, newRequest(t, http.MethodGet, url+"/_mock/state", nil))
+	expectStatus(t, resp, http.StatusOK)
+
+	var vms []VirtualMachine
+	decode(t, resp, &vms)
+	return vms
+}
+
+func TestProvision_AllocatesIncreasingIDs(t *testing.T) {
+	// Given
+	_, ts := newTestServer(t, Options{})
+	first := provision(t, ts.URL, testVM("first"))
+
+	// When
+	second := provision(t, ts.URL, testVM("second"))
+
+	// Then
+	if first != firstVMID || second != firstVMID+1 {
+		t.Errorf("IDs = %d, %d, want %d, %d", first, second, firstVMID, firstVMID+1)
+	}
+}
+
+func TestProvision_DoesNotReuseDeletedIDs(t *testing.T) {
+	// Given
+	_, ts := newTestServer(t, Options{})
+	first := provision(t, ts.URL, testVM("first"))
+	second := provision(t, ts.URL, testVM("second"))
+
+	expectStatus(t, do(t, http.MethodPost, ts.URL+"/api/virtualresource/poweroperation",
+		powerOperationPayload{VirtualResourceId: strconv.Itoa(second), Operation: "Off"}), http.StatusOK)
+	expectStatus(t, do(t, http.MethodPost, ts.URL+"/api/virtualresource/delete",
+		deleteVMPayload{VirtualResourceId: strconv.Itoa(second), CheckToken: "vm-" + strconv.Itoa(second)}), http.StatusOK)
+
+	// When
+	third := provision(t, ts.URL, testVM("third"))
+
+	// Then
+	if third <= second {
+		t.Errorf("ID after deleting %d = %d, want a new ID above it (first was %d)", second, third, first)
+	}
+}
+
+func TestProvision_SkipsSeededIDs(t *testing.T) {
+	// Given
+	seeded := testVM("seeded")
+	seeded.Id = 2000
+	_, ts := newTestServer(t, Options{Seed: []VirtualMachine{seeded}})
+
+	// When
+	id := provision(t, ts.URL, testVM("new"))
+
+	// Then
+	if id != 2001 {
+		t.Errorf("ID = %d, want 2001", id)
+	}
+}
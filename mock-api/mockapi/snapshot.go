@@ -0,0 +1,226 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Snapshot is a point-in-time copy of a VM's configuration.
+type Snapshot struct {
+	Id            string    `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	CreatedOn     time.Time `json:"createdOn"`
+	IncludeMemory bool      `json:"includeMemory"`
+	Quiesce       bool      `json:"quiesce"`
+	// State is what reverting to the snapshot restores. It is stored with
+	// the VM but not returned by the API.
+	State snapshotState `json:"state"`
+}
+
+type snapshotState struct {
+	Cores               int    `json:"cores"`
+	CoresPerSocket      int    `json:"coresPerSocket,omitempty"`
+	MemorySize          int    `json:"memorySize"`
+	MemoryMb            int    `json:"memoryMb,omitempty"`
+	OperatingSystemDisk Disk   `json:"operatingSystemDisk"`
+	AdditionalDisks     []Disk `json:"additionalDisks,omitempty"`
+	PowerState          string `json:"powerState"`
+}
+
+type snapshotResponse struct {
+	Id            string    `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	CreatedOn     time.Time `json:"createdOn"`
+	IncludeMemory bool      `json:"includeMemory"`
+	Quiesce       bool      `json:"quiesce"`
+}
+
+type createSnapshotPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IncludeMemory     bool   `json:"includeMemory"`
+	Quiesce           bool   `json:"quiesce"`
+}
+
+type snapshotOperationPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	SnapshotId        string `json:"snapshotId"`
+}
+
+func (s *Server) listSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenant, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	vm, ok := s.loadTenantVM(w, tenant, path.Base(r.URL.Path))
+	s.vmMutex.Unlock()
+	if !ok {
+		return
+	}
+
+	response := []snapshotResponse{}
+	for _, snapshot := range vm.Snapshots {
+		response = append(response, snapshotResponse{
+			Id:            snapshot.Id,
+			Name:          snapshot.Name,
+			Description:   snapshot.Description,
+			CreatedOn:     snapshot.CreatedOn,
+			IncludeMemory: snapshot.IncludeMemory,
+			Quiesce:       snapshot.Quiesce,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) createSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	var payload createSnapshotPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	if payload.Name == "" {
+		handleError(w, "Snapshot name is required", http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	// Memory can only be captured from a running VM.
+	if payload.IncludeMemory && vm.PowerState == powerStateOff {
+		handleError(w, "Cannot include memory in a snapshot of a powered off VM", http.StatusConflict)
+		return
+	}
+
+	snapshot := Snapshot{
+		Id:            "snapshot-" + uuid.New().String(),
+		Name:          payload.Name,
+		Description:   payload.Description,
+		CreatedOn:     time.Now().UTC(),
+		IncludeMemory: payload.IncludeMemory,
+		Quiesce:       payload.Quiesce,
+		State: snapshotState{
+			Cores:               vm.Cores,
+			CoresPerSocket:      vm.CoresPerSocket,
+			MemorySize:          vm.MemorySize,
+			OperatingSystemDisk: vm.OperatingSystemDisk,
+			AdditionalDisks:     append([]Disk(nil), vm.AdditionalDisks...),
+			PowerState:          vm.PowerState,
+		},
+	}
+	vm.Snapshots = append(vm.Snapshots, snapshot)
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Created snapshot %s of VM %d", snapshot.Id, vm.Id)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	var payload snapshotOperationPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	index := findSnapshot(vm, payload.SnapshotId)
+	if index < 0 {
+		handleError(w, "Snapshot not found", http.StatusNotFound)
+		return
+	}
+	vm.Snapshots = append(vm.Snapshots[:index], vm.Snapshots[index+1:]...)
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// revertSnapshotHandler restores the VM's configuration from a snapshot. A
+// snapshot without memory leaves the VM powered off, as vCenter does.
+func (s *Server) revertSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	var payload snapshotOperationPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	index := findSnapshot(vm, payload.SnapshotId)
+	if index < 0 {
+		handleError(w, "Snapshot not found", http.StatusNotFound)
+		return
+	}
+
+	snapshot := vm.Snapshots[index]
+	vm.Cores = snapshot.State.Cores
+	vm.CoresPerSocket = snapshot.State.CoresPerSocket
+	vm.MemorySize = snapshot.State.MemorySize
+	vm.MemoryMb = snapshot.State.MemoryMb
+	vm.OperatingSystemDisk = snapshot.State.OperatingSystemDisk
+	vm.AdditionalDisks = append([]Disk(nil), snapshot.State.AdditionalDisks...)
+	vm.PowerState = powerStateOff
+	if snapshot.IncludeMemory {
+		vm.PowerState = snapshot.State.PowerState
+	}
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Reverted VM %d to snapshot %s", vm.Id, snapshot.Id)
+	w.WriteHeader(http.StatusOK)
+}
+
+func findSnapshot(vm VirtualMachine, snapshotID string) int {
+	for i, snapshot := range vm.Snapshots {
+		if snapshot.Id == snapshotID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,240 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var errVMNotFound = errors.New("VM not found")
+
+// store persists the mock's VMs. Callers must hold Server.vmMutex.
+type store interface {
+	save(vm VirtualMachine) error
+	load(vmID string) (VirtualMachine, error)
+	delete(vmID string) error
+	list() ([]VirtualMachine, error)
+	reset() error
+}
+
+// firstVMID is the ID given to the first VM provisioned in an empty store.
+const firstVMID = 1000
+
+// nextVMID returns an ID higher than any VM stored since the server started,
+// so IDs are never reused, even after a VM is deleted. The caller must hold
+// vmMutex.
+func (s *Server) nextVMID() (int, error) {
+	if s.lastVMID == 0 {
+		vms, err := s.store.list()
+		if err != nil {
+			return 0, err
+		}
+
+		s.lastVMID = firstVMID - 1
+		for _, vm := range vms {
+			s.lastVMID = max(s.lastVMID, vm.Id)
+		}
+	}
+
+	// Seed VMs reloaded by a reset can have any ID, so skip those in use.
+	for {
+		s.lastVMID++
+		if _, err := s.store.load(strconv.Itoa(s.lastVMID)); errors.Is(err, errVMNotFound) {
+			return s.lastVMID, nil
+		} else if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// fileStore keeps each VM in <dataDir>/<id>.json. Only files named after a
+// numeric ID are read, so other JSON files in the directory are ignored.
+type fileStore struct {
+	dataDir string
+}
+
+func (f fileStore) fileName(vmID string) string {
+	return filepath.Join(f.dataDir, fmt.Sprintf("%s.json", vmID))
+}
+
+func (f fileStore) save(vm VirtualMachine) error {
+	file, err := json.MarshalIndent(vm, "", " ")
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Saving VM file %d.json", vm.Id)
+	return os.WriteFile(f.fileName(strconv.Itoa(vm.Id)), file, 0644)
+}
+
+func (f fileStore) load(vmID string) (VirtualMachine, error) {
+	if _, err := strconv.Atoi(vmID); err != nil {
+		return VirtualMachine{}, errVMNotFound
+	}
+
+	fileName := f.fileName(vmID)
+	data, err := os.ReadFile(fileName)
+	log.Printf("Reading json file %s", fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return VirtualMachine{}, errVMNotFound
+	} else if err != nil {
+		return VirtualMachine{}, err
+	}
+
+	var vm VirtualMachine
+	err = json.Unmarshal(data, &vm)
+	if err != nil {
+		return VirtualMachine{}, err
+	}
+
+	return vm, nil
+}
+
+func (f fileStore) delete(vmID string) error {
+	if _, err := strconv.Atoi(vmID); err != nil {
+		return errVMNotFound
+	}
+
+	log.Printf("Deleting VM file %s.json", vmID)
+	err := os.Remove(f.fileName(vmID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return errVMNotFound
+	}
+	return err
+}
+
+// vmIDs returns the IDs of the VM files in dataDir.
+func (f fileStore) vmIDs() ([]string, error) {
+	files, err := os.ReadDir(f.dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, file := range files {
+		id, ok := strings.CutSuffix(file.Name(), ".json")
+		if file.IsDir() || !ok {
+			continue
+		}
+		if _, err := strconv.Atoi(id); err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (f fileStore) list() ([]VirtualMachine, error) {
+	log.Printf("Reading all json files")
+	ids, err := f.vmIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var vms []VirtualMachine
+	for _, id := range ids {
+		vm, err := f.load(id)
+		if err != nil {
+			log.Printf("Error parsing VM file %s.json: %v\n", id, err)
+			continue
+		}
+		vms = append(vms, vm)
+	}
+	return vms, nil
+}
+
+func (f fileStore) reset() error {
+	ids, err := f.vmIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := f.delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memoryStore keeps VMs in memory, so nothing is written to disk. VMs are
+// stored as JSON, as fileStore does, so loaded VMs never share slices with
+// the stored ones or with each other.
+type memoryStore struct {
+	vms map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{vms: make(map[string][]byte)}
+}
+
+func (m *memoryStore) save(vm VirtualMachine) error {
+	data, err := json.Marshal(vm)
+	if err != nil {
+		return err
+	}
+
+	m.vms[strconv.Itoa(vm.Id)] = data
+	return nil
+}
+
+func (m *memoryStore) load(vmID string) (VirtualMachine, error) {
+	data, ok := m.vms[vmID]
+	if !ok {
+		return VirtualMachine{}, errVMNotFound
+	}
+
+	var vm VirtualMachine
+	if err := json.Unmarshal(data, &vm); err != nil {
+		return VirtualMachine{}, err
+	}
+	return vm, nil
+}
+
+func (m *memoryStore) delete(vmID string) error {
+	if _, ok := m.vms[vmID]; !ok {
+		return errVMNotFound
+	}
+	delete(m.vms, vmID)
+	return nil
+}
+
+func (m *memoryStore) list() ([]VirtualMachine, error) {
+	vms := make([]VirtualMachine, 0, len(m.vms))
+	for id := range m.vms {
+		vm, err := m.load(id)
+		if err != nil {
+			return nil, err
+		}
+		vms = append(vms, vm)
+	}
+	sort.Slice(vms, func(i, j int) bool { return vms[i].Id < vms[j].Id })
+	return vms, nil
+}
+
+func (m *memoryStore) reset() error {
+	m.vms = make(map[string][]byte)
+	return nil
+}
+
+// LoadSeed reads a JSON array of VMs, in the format returned by
+// GET /_mock/state, to load when the server starts or is reset.
+func LoadSeed(fileName string) ([]VirtualMachine, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var vms []VirtualMachine
+	if err := json.Unmarshal(data, &vms); err != nil {
+		return nil, fmt.Errorf("error parsing seed file %s: %w", fileName, err)
+	}
+	return vms, nil
+}
@@ -0,0 +1,70 @@
+package mockapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryStore_LoadReturnsCopy(t *testing.T) {
+	// Given
+	store := newMemoryStore()
+	vm := testVM("copy")
+	vm.Id = 1000
+	vm.AdditionalDisks = []Disk{{Capacity: 10, StorageProfile: "vStorageT1"}}
+	if err := store.save(vm); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	// When
+	vm.AdditionalDisks[0].Capacity = 20
+	loaded, err := store.load("1000")
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	loaded.AdditionalDisks[0].Capacity = 30
+
+	// Then
+	reloaded, err := store.load("1000")
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if got := reloaded.AdditionalDisks[0].Capacity; got != 10 {
+		t.Errorf("stored disk capacity = %d, want 10", got)
+	}
+}
+
+func TestMemoryStore_LoadMissing(t *testing.T) {
+	// Given
+	store := newMemoryStore()
+
+	// When
+	_, err := store.load("1000")
+
+	// Then
+	if !errors.Is(err, errVMNotFound) {
+		t.Errorf("load() error = %v, want %v", err, errVMNotFound)
+	}
+}
+
+func TestFileStore_SaveAndList(t *testing.T) {
+	// Given
+	store := fileStore{dataDir: t.TempDir()}
+	for _, id := range []int{1001, 1000} {
+		vm := testVM("file")
+		vm.Id = id
+		if err := store.save(vm); err != nil {
+			t.Fatalf("save() error = %v", err)
+		}
+	}
+
+	// When
+	vms, err := store.list()
+
+	// Then
+	if err != nil {
+		t.Fatalf("list() error = %v", err)
+	}
+	if len(vms) != 2 || vms[0].Id != 1000 || vms[1].Id != 1001 {
+		t.Errorf("list() = %+v, want VMs 1000 and 1001 in order", vms)
+	}
+}
@@ -0,0 +1,77 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// task is a job started by an operation, reported by GET /api/Task/{id}.
+type task struct {
+	Id                string
+	VirtualResourceId int
+	CompleteAt        time.Time
+}
+
+// startTask records a task that completes after the configured
+// TaskDuration and returns its ID.
+func (s *Server) startTask(vmID int) string {
+	t := task{
+		Id:                uuid.New().String(),
+		VirtualResourceId: vmID,
+		CompleteAt:        time.Now().Add(time.Duration(s.currentFaults().TaskDuration)),
+	}
+
+	s.taskMutex.Lock()
+	s.tasks[t.Id] = t
+	s.taskMutex.Unlock()
+
+	return t.Id
+}
+
+func (s *Server) resetTasks() {
+	s.taskMutex.Lock()
+	s.tasks = make(map[string]task)
+	s.taskMutex.Unlock()
+}
+
+// writeTask responds to an operation with the task it started.
+func writeTask(w http.ResponseWriter, taskID string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"taskId": taskID})
+}
+
+func (s *Server) getTaskHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	s.taskMutex.Lock()
+	t, ok := s.tasks[path.Base(r.URL.Path)]
+	s.taskMutex.Unlock()
+	if !ok {
+		handleError(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	status := "Running"
+	if !time.Now().Before(t.CompleteAt) {
+		status = "Completed"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":                t.Id,
+		"status":            status,
+		"message":           "",
+		"virtualResourceId": t.VirtualResourceId,
+	})
+}
@@ -0,0 +1,350 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func hasMissingRequiredFields(vm VirtualMachine) (bool, string) {
+	fields := []struct {
+		value string
+		name  string
+	}{
+		{fmt.Sprint(vm.ClientId), "ClientId"},
+		{vm.Name, "Name"},
+		{vm.GuestOsId, "GuestOsId"},
+		{fmt.Sprint(vm.Cores), "Cores"},
+		{fmt.Sprint(memoryMb(vm)), "MemorySize"},
+		{vm.OperatingSystemDisk.StorageProfile, "OperatingSystemDisk.StorageProfile"},
+		{vm.HostingLocation.Id, "HostingLocation.Id"},
+		{vm.HostingLocation.Name, "HostingLocation.Name"},
+		{vm.HostingLocation.DefaultNetwork, "HostingLocation.DefaultNetwork"},
+		{vm.BackupType, "BackupType"},
+	}
+
+	for _, field := range fields {
+		if field.value == "" || field.value == "0" {
+			return true, field.name
+		}
+	}
+
+	return false, ""
+}
+
+func (s *Server) provisionVMHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenant, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var vm VirtualMachine
+	err := json.NewDecoder(r.Body).Decode(&vm)
+	if err != nil {
+		handleError(w, fmt.Sprintf("Error parsing VM file %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if missing, field := hasMissingRequiredFields(vm); missing {
+		handleError(w, fmt.Sprintf("Missing required field: %s", field), http.StatusBadRequest)
+		return
+	}
+
+	if !authorizeClient(w, tenant, vm.ClientId) {
+		return
+	}
+
+	if vm.CoresPerSocket < 0 || (vm.CoresPerSocket > 0 && vm.Cores%vm.CoresPerSocket != 0) {
+		handleError(w, fmt.Sprintf("Cores (%d) must be a multiple of CoresPerSocket (%d)", vm.Cores, vm.CoresPerSocket), http.StatusBadRequest)
+		return
+	}
+
+	if msg := checkMemory(vm.HostingLocation.Id, memoryMb(vm)); msg != "" {
+		handleError(w, msg, http.StatusBadRequest)
+		return
+	}
+	setMemory(&vm, memoryMb(vm))
+
+	if _, ok := tierNames[vm.OperatingSystemDisk.StorageProfile]; !ok {
+		handleError(w, fmt.Sprintf("Unknown storage profile: %s", vm.OperatingSystemDisk.StorageProfile), http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vmID, err := s.nextVMID()
+	if err != nil {
+		handleError(w, "Error allocating VM ID", http.StatusInternalServerError)
+		return
+	}
+
+	vm.Id = vmID
+	vm.MoRef = fmt.Sprintf("vm-%d", vmID)
+	vm.OperatingSystemDisk.MoRef = uuid.New().String()
+	vm.PowerState = powerStateOn
+	vm.ProvisionedAt = time.Now()
+
+	if vm.Template == "Windows2022_Standard_30GB" {
+		vm.OperatingSystemDisk.Capacity = 30
+	}
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getAllVMsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenant, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	clientId, err := strconv.Atoi(path.Base(r.URL.Path))
+	if err != nil {
+		handleError(w, "Invalid client id", http.StatusBadRequest)
+		return
+	}
+
+	if !authorizeClient(w, tenant, clientId) {
+		return
+	}
+
+	s.vmMutex.Lock()
+	allVMs, err := s.store.list()
+	s.vmMutex.Unlock()
+	if err != nil {
+		handleError(w, "Error loading VMs", http.StatusInternalServerError)
+		return
+	}
+
+	listDelay := time.Duration(s.currentFaults().ListDelay)
+	response := []map[string]interface{}{}
+	for _, vm := range allVMs {
+		if vm.ClientId == clientId && time.Since(vm.ProvisionedAt) >= listDelay {
+			response = append(response, createVMListResponse(vm))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getVMDetailedByIDHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenant, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	vmID := path.Base(r.URL.Path)
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) != 5 {
+		handleError(w, "Invalid URL path", http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	vm, ok := s.loadTenantVM(w, tenant, vmID)
+	s.vmMutex.Unlock()
+	if !ok {
+		return
+	}
+
+	if time.Since(vm.ProvisionedAt) < time.Duration(s.currentFaults().DetailedDelay) {
+		handleError(w, "VM not found", http.StatusNotFound)
+		return
+	}
+
+	if s.takeDropDisks() {
+		log.Printf("Dropping additional disks from VM %d detail response", vm.Id)
+		vm.AdditionalDisks = nil
+	}
+
+	response := createVMDetailResponse(vm)
+	log.Printf("Vm Detail Response %s", response)
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		handleError(w, "Error encoding JSON response", http.StatusInternalServerError)
+	}
+}
+
+type powerOperationPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Operation         string `json:"Operation"`
+}
+
+// powerOperations maps each supported operation to the power state the VM
+// is left in.
+var powerOperations = map[string]string{
+	"on":       powerStateOn,
+	"off":      powerStateOff,
+	"shutdown": powerStateOff,
+	"reset":    powerStateOn,
+	"restart":  powerStateOn,
+}
+
+func (s *Server) powerOperationHandler(w http.ResponseWriter, r *http.Request) {
+	var payload powerOperationPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	powerState, ok := powerOperations[strings.ToLower(payload.Operation)]
+	if !ok {
+		handleError(w, fmt.Sprintf("Unknown power operation: %s", payload.Operation), http.StatusBadRequest)
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	// Only a VM that is running can be reset or restarted.
+	if vm.PowerState == powerStateOff && powerState == powerStateOn && strings.ToLower(payload.Operation) != "on" {
+		handleError(w, "VM is powered off", http.StatusConflict)
+		return
+	}
+
+	log.Printf("Power operation %s on VM %d: %s -> %s", payload.Operation, vm.Id, vm.PowerState, powerState)
+	vm.PowerState = powerState
+
+	if err := s.store.save(vm); err != nil {
+		handleError(w, "Error saving VM details", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type deleteVMPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	CheckToken        string `json:"CheckToken"`
+}
+
+func (s *Server) deleteVMHandler(w http.ResponseWriter, r *http.Request) {
+	var payload deleteVMPayload
+	tenant, ok := s.decodeOperation(w, r, &payload)
+	if !ok {
+		return
+	}
+
+	s.vmMutex.Lock()
+	defer s.vmMutex.Unlock()
+
+	vm, ok := s.loadTenantVM(w, tenant, payload.VirtualResourceId)
+	if !ok {
+		return
+	}
+
+	// The API uses the VM's MoRef as a check token so a stale or mistyped
+	// ID can't delete the wrong VM.
+	if payload.CheckToken != vm.MoRef {
+		handleError(w, "CheckToken does not match the VM", http.StatusBadRequest)
+		return
+	}
+
+	if vm.PowerState != powerStateOff {
+		handleError(w, "VM must be powered off before it is deleted", http.StatusConflict)
+		return
+	}
+
+	if err := s.store.delete(payload.VirtualResourceId); err != nil {
+		handleError(w, "Error deleting VM", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// decodeOperation checks the method and credentials of a POST operation and
+// decodes its payload, writing an error response and returning false if any
+// of those fail.
+func (s *Server) decodeOperation(w http.ResponseWriter, r *http.Request, payload interface{}) (Tenant, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return Tenant{}, false
+	}
+
+	tenant, ok := s.authenticate(w, r)
+	if !ok {
+		return Tenant{}, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		handleError(w, fmt.Sprintf("Error parsing request %s", err), http.StatusBadRequest)
+		return Tenant{}, false
+	}
+
+	return tenant, true
+}
+
+// loadTenantVM loads a VM belonging to one of tenant's clients, writing a 404
+// or 403 and returning false if it can't. The caller must hold vmMutex.
+func (s *Server) loadTenantVM(w http.ResponseWriter, tenant Tenant, vmID string) (VirtualMachine, bool) {
+	vm, err := s.store.load(vmID)
+	if err != nil {
+		if errors.Is(err, errVMNotFound) {
+			handleError(w, "VM not found", http.StatusNotFound)
+		} else {
+			handleError(w, "Error reading VM file", http.StatusInternalServerError)
+		}
+		return VirtualMachine{}, false
+	}
+
+	if !authorizeClient(w, tenant, vm.ClientId) {
+		return VirtualMachine{}, false
+	}
+
+	return vm, true
+}
+
+func createVMListResponse(vm VirtualMachine) map[string]interface{} {
+	return map[string]interface{}{
+		"clientId":            vm.ClientId,
+		"name":                vm.Name,
+		"template":            vm.Template,
+		"guestOsId":           vm.GuestOsId,
+		"cores":               vm.Cores,
+		"memorySize":          vm.MemorySize,
+		"operatingSystemDisk": vm.OperatingSystemDisk,
+		"additionalDisks":     vm.AdditionalDisks,
+		"isoFile":             vm.IsoFile,
+		"quoteItem":           vm.QuoteItem,
+		"backupType":          vm.BackupType,
+		"id":                  vm.Id,
+		"hostingLocation":     vm.HostingLocation.Name,
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tagsHeader separates a VM's notes from its tags in the annotation. vBridge
+// has no tag API, so tags are stored as key=value lines after it:
+//
+//	Web server for the intranet
+//
+//	[tags]
+//	cost-centre=1234
+//	owner=platform
+const tagsHeader = "[tags]"
+
+// EncodeAnnotation returns the annotation holding notes and tags, with the
+// tags sorted by key.
+func EncodeAnnotation(notes string, tags map[string]string) string {
+	if len(tags) == 0 {
+		return notes
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	if notes != "" {
+		b.WriteString(notes)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(tagsHeader)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n%s=%s", key, tags[key])
+	}
+	return b.String()
+}
+
+// DecodeAnnotation splits an annotation written by EncodeAnnotation into
+// notes and tags. Annotations without tags are returned as notes.
+func DecodeAnnotation(annotation string) (string, map[string]string) {
+	tags := make(map[string]string)
+
+	notes, tagLines, found := strings.Cut(annotation, "\n\n"+tagsHeader+"\n")
+	if !found {
+		tagLines, found = strings.CutPrefix(annotation, tagsHeader+"\n")
+		if !found {
+			return annotation, tags
+		}
+		notes = ""
+	}
+
+	for _, line := range strings.Split(tagLines, "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			tags[key] = value
+		}
+	}
+	return notes, tags
+}
+
+// ValidateTag returns an error if a tag can't be stored in an annotation.
+func ValidateTag(key, value string) error {
+	if key == "" || strings.ContainsAny(key, "=\n") {
+		return fmt.Errorf("tag keys must be non-empty and can't contain '=' or newlines, got: %q", key)
+	}
+	if strings.Contains(value, "\n") {
+		return fmt.Errorf("tag values can't contain newlines, got: %q", value)
+	}
+	return nil
+}
+
+// SetAnnotation replaces a VM's annotation and waits for the detailed
+// endpoint to report it.
+func (c *Client) SetAnnotation(ctx context.Context, vmID string, annotation string) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/UpdateAnnotation"
+	payload := UpdateAnnotationPayload{
+		VirtualResourceId: vmID,
+		Annotation:        annotation,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("updating annotation of VM %s", vmID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+		return vm.Annotation == annotation, "annotation pending", nil
+	})
+}
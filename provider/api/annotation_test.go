@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeAnnotation(t *testing.T) {
+	// Given
+	tags := map[string]string{"owner": "platform", "cost-centre": "1234"}
+
+	// When
+	result := EncodeAnnotation("Web server\nfor the intranet", tags)
+
+	// Then
+	assert.Equal(t, "Web server\nfor the intranet\n\n[tags]\ncost-centre=1234\nowner=platform", result)
+}
+
+func TestEncodeAnnotation_NoTags(t *testing.T) {
+	// When
+	result := EncodeAnnotation("Web server", nil)
+
+	// Then
+	assert.Equal(t, "Web server", result)
+}
+
+func TestDecodeAnnotation_RoundTrip(t *testing.T) {
+	cases := []struct {
+		notes string
+		tags  map[string]string
+	}{
+		{notes: "", tags: map[string]string{}},
+		{notes: "Web server", tags: map[string]string{}},
+		{notes: "", tags: map[string]string{"owner": "platform"}},
+		{notes: "Multi\n\nline notes", tags: map[string]string{"owner": "platform", "empty": ""}},
+	}
+
+	for _, c := range cases {
+		// When
+		notes, tags := DecodeAnnotation(EncodeAnnotation(c.notes, c.tags))
+
+		// Then
+		assert.Equal(t, c.notes, notes, "Notes mismatch")
+		assert.Equal(t, c.tags, tags, "Tags mismatch")
+	}
+}
+
+func TestDecodeAnnotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		wantNotes  string
+		wantTags   map[string]string
+	}{
+		{"empty", "", "", map[string]string{}},
+		{"tags only", "[tags]\nowner=platform", "", map[string]string{"owner": "platform"}},
+		{"notes and tags", "Web server\n\n[tags]\nowner=platform", "Web server", map[string]string{"owner": "platform"}},
+		{"value containing =", "[tags]\nquery=a=b", "", map[string]string{"query": "a=b"}},
+		{"line without =", "[tags]\nowner=platform\nstray", "", map[string]string{"owner": "platform"}},
+		{"header within the notes", "See\n[tags]\nowner=platform", "See\n[tags]\nowner=platform", map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			notes, tags := DecodeAnnotation(tt.annotation)
+
+			// Then
+			assert.Equal(t, tt.wantNotes, notes)
+			assert.Equal(t, tt.wantTags, tags)
+		})
+	}
+}
+
+func TestDecodeAnnotation_PortalNotes(t *testing.T) {
+	// When
+	notes, tags := DecodeAnnotation("Restored from backup 2024-08-16\nby the service desk")
+
+	// Then
+	assert.Equal(t, "Restored from backup 2024-08-16\nby the service desk", notes)
+	assert.Empty(t, tags)
+}
+
+func TestValidateTag(t *testing.T) {
+	assert.NoError(t, ValidateTag("cost-centre", "1234"))
+	assert.Error(t, ValidateTag("", "1234"))
+	assert.Error(t, ValidateTag("a=b", "1234"))
+	assert.Error(t, ValidateTag("owner", "two\nlines"))
+}
+
+func TestSetAnnotation(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+	var payload UpdateAnnotationPayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/UpdateAnnotation
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/UpdateAnnotation" {
+			json.NewDecoder(r.Body).Decode(&payload)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the annotation updating on the 2nd call
+			getVMDetailedByIDCalls++
+			annotation := ""
+			if getVMDetailedByIDCalls >= 2 {
+				annotation = payload.Annotation
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 12345, "annotation": annotation})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.SetAnnotation(context.Background(), "12345", "[tags]\nowner=platform")
+
+	// Then
+	assert.NoError(t, err, "expected no error from SetAnnotation")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+	assert.Equal(t, UpdateAnnotationPayload{VirtualResourceId: "12345", Annotation: "[tags]\nowner=platform"}, payload, "Payload mismatch")
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached lookup is reused. It is kept short so
+// a cached value never outlives a single plan or apply by much.
+const DefaultCacheTTL = 30 * time.Second
+
+type cacheEntry[V any] struct {
+	ready   chan struct{}
+	value   V
+	err     error
+	expires time.Time
+}
+
+// readThroughCache caches the result of a fetch per key for a fixed TTL.
+// Concurrent callers for the same key share a single in-flight fetch. The
+// zero value is ready to use.
+type readThroughCache[V any] struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry[V]
+}
+
+// get returns the cached value for key, calling fetch if there is none or it
+// has expired. A ttl of zero disables caching.
+func (c *readThroughCache[V]) get(key string, ttl time.Duration, fetch func() (V, error)) (V, error) {
+	if ttl <= 0 {
+		return fetch()
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]*cacheEntry[V])
+	}
+
+	if entry, ok := c.entries[key]; ok {
+		select {
+		case <-entry.ready:
+			if entry.err == nil && time.Now().Before(entry.expires) {
+				c.mu.Unlock()
+				return entry.value, nil
+			}
+		default:
+			// Another caller is already fetching this key.
+			c.mu.Unlock()
+			<-entry.ready
+			return entry.value, entry.err
+		}
+	}
+
+	entry := &cacheEntry[V]{ready: make(chan struct{})}
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	entry.value, entry.err = fetch()
+	entry.expires = time.Now().Add(ttl)
+	close(entry.ready)
+
+	if entry.err != nil {
+		c.mu.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+	}
+
+	return entry.value, entry.err
+}
+
+// invalidate drops the cached value for key. A fetch already in flight still
+// completes for its callers but is not reused afterwards.
+func (c *readThroughCache[V]) invalidate(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+func (c *readThroughCache[V]) invalidateAll() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+// invalidateVM drops the cached detailed lookup for a VM. It is called after
+// every mutating request and before each poll so waits see fresh data.
+func (c *Client) invalidateVM(vmID string) {
+	c.detailedCache.invalidate(vmID)
+}
+
+// invalidateVirtualResources drops every cached virtual resource list.
+func (c *Client) invalidateVirtualResources() {
+	c.listCache.invalidateAll()
+}
+
+// copyVM returns vm with its slices copied so callers can't modify the
+// cached value.
+func copyVM(vm VirtualMachine) VirtualMachine {
+	vm.Specification.VirtualDisks = append([]VirtualDisk(nil), vm.Specification.VirtualDisks...)
+	vm.Specification.NetworkDevices = append([]NetworkDevice(nil), vm.Specification.NetworkDevices...)
+	vm.Specification.InstantRecoveryDisks = append([]InstantRecoveryDisk(nil), vm.Specification.InstantRecoveryDisks...)
+	vm.Licenses = append([]License(nil), vm.Licenses...)
+	return vm
+}
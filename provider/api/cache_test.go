@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVMDetailedByID_Cached(t *testing.T) {
+	// Counter to track the number of detailed fetches
+	var getVMDetailedByIDCalls int32
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			atomic.AddInt32(&getVMDetailedByIDCalls, 1)
+			time.Sleep(5 * time.Millisecond)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":   12345,
+				"name": "DISKVM0000",
+				"specification": map[string]interface{}{
+					"virtualDisks": []map[string]interface{}{
+						{"moRef": "disk-100", "capacity": 100.0, "tier": "Performance"},
+					},
+				},
+			})
+			return
+		}
+
+		// Handle POST /api/virtualresource/poweroperation
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/poweroperation" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+	client.CacheTTL = time.Minute
+
+	// When
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetVMDisk(context.Background(), "12345", "disk-100")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	cachedCalls := atomic.LoadInt32(&getVMDetailedByIDCalls)
+
+	err := client.PowerOffVM(context.Background(), "12345")
+	assert.NoError(t, err)
+	_, err = client.GetVMDetailedByID(context.Background(), "12345")
+	assert.NoError(t, err)
+
+	// Then
+	assert.Equal(t, int32(1), cachedCalls, "expected concurrent lookups to share one fetch")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&getVMDetailedByIDCalls), "expected a mutating call to invalidate the cache")
+}
+
+func TestGetVMDetailedByID_CacheReturnsCopy(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 12345, "specification": {"virtualDisks": [{"moRef": "disk-100", "capacity": 100.0}]}}`))
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+	client.CacheTTL = time.Minute
+
+	// When
+	first, err := client.GetVMDetailedByID(context.Background(), "12345")
+	assert.NoError(t, err)
+	first.Specification.VirtualDisks[0].Capacity = 1
+
+	second, err := client.GetVMDetailedByID(context.Background(), "12345")
+	assert.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 100, second.Specification.VirtualDisks[0].Capacity, "expected cached value to be unchanged")
+}
@@ -2,27 +2,122 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/time/rate"
 )
 
+// ErrNotFound is returned (wrapped) when a looked up resource does not exist.
+var ErrNotFound = errors.New("not found")
+
+// APIError is returned (wrapped) when the API responds with a status other
+// than 200. A 404 also matches ErrNotFound.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("error response from API: %s - %s", e.Status, e.Body)
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// isTransient reports whether a request that failed with err might succeed
+// if retried: the API was throttling or failing, or couldn't be reached.
+// Other errors, such as authentication failures, fail a wait straight away.
+func isTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
 type Client struct {
-	APIUrl    string
-	APIKey    string
-	UserEmail string
+	APIUrl       string
+	APIKey       string
+	UserEmail    string
+	PollInterval time.Duration
+	// CacheTTL is how long detailed VM and virtual resource list lookups are
+	// reused. Zero disables caching.
+	CacheTTL time.Duration
+	// WaitTimeout bounds each wait for an asynchronous operation, on top of
+	// the caller's context. Zero relies on the context alone.
+	WaitTimeout time.Duration
+
+	vmLocks       keyedLock
+	limiter       *rate.Limiter
+	inflight      chan struct{}
+	detailedCache readThroughCache[VirtualMachine]
+	listCache     readThroughCache[[]VirtualResourceSummary]
+	limitsCache   readThroughCache[HostingLocationLimits]
 }
 
 func NewClient(apiURL, apiKey, userEmail string) (*Client, error) {
 	return &Client{
-		APIUrl:    apiURL,
-		APIKey:    apiKey,
-		UserEmail: userEmail,
+		APIUrl:       apiURL,
+		APIKey:       apiKey,
+		UserEmail:    userEmail,
+		PollInterval: DefaultPollInterval,
+		CacheTTL:     DefaultCacheTTL,
+		WaitTimeout:  DefaultWaitTimeout,
 	}, nil
 }
 
-func (c *Client) apiRequest(method, endpoint string, payload interface{}) (*http.Response, error) {
+// SetRateLimit limits the client to requestsPerSecond requests per second and
+// at most maxConcurrent requests in flight. Zero disables either limit. It
+// should be called before the client is shared between resources.
+func (c *Client) SetRateLimit(requestsPerSecond float64, maxConcurrent int) {
+	c.limiter = nil
+	if requestsPerSecond > 0 {
+		burst := int(math.Ceil(requestsPerSecond))
+		c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+
+	c.inflight = nil
+	if maxConcurrent > 0 {
+		c.inflight = make(chan struct{}, maxConcurrent)
+	}
+}
+
+// acquire waits for a free request slot and a rate limiter token. The
+// returned function releases the slot.
+func (c *Client) acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+	if c.inflight != nil {
+		select {
+		case c.inflight <- struct{}{}:
+			release = func() { <-c.inflight }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+func (c *Client) apiRequest(ctx context.Context, method, endpoint string, payload interface{}) (*http.Response, error) {
 	var body io.Reader
 	if payload != nil {
 		jsonData, err := json.Marshal(payload)
@@ -33,7 +128,7 @@ func (c *Client) apiRequest(method, endpoint string, payload interface{}) (*http
 	}
 
 	url := fmt.Sprintf("%s%s", c.APIUrl, endpoint)
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("error creating HTTP request: %w", err)
 	}
@@ -42,16 +137,32 @@ func (c *Client) apiRequest(method, endpoint string, payload interface{}) (*http
 	req.Header.Set("x-mcs-user", c.UserEmail)
 	req.Header.Set("Content-Type", "application/json")
 
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting to make HTTP request: %w", err)
+	}
+
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
+		release()
 		return nil, fmt.Errorf("error making HTTP request: %w", err)
 	}
 
+	// The body is read up front so the slot is freed before callers go on to
+	// poll for the operation to finish. Holding it until the body is closed
+	// would let max_concurrent_requests parallel operations starve their own
+	// polls.
+	bodyBytes, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("error reading HTTP response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("error response from API: %s - %s", resp.Status, string(bodyBytes))
+		return nil, &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(bodyBytes)}
 	}
 
 	return resp, nil
@@ -1,9 +1,35 @@
 package api
 
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"localhost-api/mockapi"
+)
+
 func testClient(apiUrl string) *Client {
 	return &Client{
-		APIUrl:    apiUrl,
-		APIKey:    "dummy-key",
-		UserEmail: "user@example.com",
+		APIUrl:       apiUrl,
+		APIKey:       "dummy-key",
+		UserEmail:    "user@example.com",
+		PollInterval: 10 * time.Millisecond,
+	}
+}
+
+// replayServer serves the interactions recorded in testdata/<cassette> with
+// mock-api's replay mode.
+func replayServer(t *testing.T, cassette string) *httptest.Server {
+	t.Helper()
+
+	recorded, err := mockapi.LoadCassette(filepath.Join("testdata", cassette))
+	if err != nil {
+		t.Fatal(err)
 	}
+
+	server := httptest.NewServer(mockapi.NewReplayer(recorded).Handler())
+	t.Cleanup(server.Close)
+
+	return server
 }
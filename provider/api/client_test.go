@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRateLimit_MaxConcurrentRequests(t *testing.T) {
+	// Counters to track the number of requests in flight
+	var inFlight, maxInFlight int32
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+	client.SetRateLimit(0, 2)
+
+	// When
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := client.PowerOffVM(context.Background(), "7452")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	assert.LessOrEqual(t, maxInFlight, int32(2), "expected at most 2 requests in flight")
+}
+
+func TestSetRateLimit_ParallelOperationsPollWithinCap(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/UpdateCPU
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/UpdateCPU" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/api/VirtualResource/Detailed/") {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"specification": {"cores": 1, "sockets": 4}}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+	client.SetRateLimit(0, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// When
+	// Each operation polls after its request, so more operations than slots
+	// only finish if the slot is freed before polling
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(vmID string) {
+			defer wg.Done()
+			err := client.UpdateCPU(ctx, vmID, 4, 1)
+			assert.NoError(t, err)
+		}(fmt.Sprint(7450 + i))
+	}
+	wg.Wait()
+
+	// Then
+	assert.NoError(t, ctx.Err(), "expected the operations to finish before the timeout")
+}
+
+func TestSetRateLimit_RequestsPerSecond(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+	client.SetRateLimit(100, 0)
+
+	// When
+	// The first 100 requests use the burst, the next 10 wait ~10ms each
+	start := time.Now()
+	for i := 0; i < 110; i++ {
+		err := client.PowerOffVM(context.Background(), "7452")
+		assert.NoError(t, err)
+	}
+
+	// Then
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond, "expected requests beyond the burst to be throttled")
+}
+
+func TestAPIError_NotFound(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "VM not found", http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.GetVMDetailedByID(context.Background(), "7452")
+
+	// Then
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.False(t, isTransient(err), "expected a 404 not to be transient")
+	assert.True(t, isTransient(&APIError{StatusCode: http.StatusServiceUnavailable}), "expected a 503 to be transient")
+}
@@ -0,0 +1,58 @@
+package api
+
+import "strings"
+
+// guestOSNames maps vSphere guest OS identifiers, which are sent when a VM
+// is provisioned, to the names the detailed endpoint reports them by.
+var guestOSNames = map[string]string{
+	"windows8Server64Guest":      "Microsoft Windows Server 2012 (64-bit)",
+	"windows9Server64Guest":      "Microsoft Windows Server 2016 (64-bit)",
+	"windows2019srv_64Guest":     "Microsoft Windows Server 2019 (64-bit)",
+	"windows2019srvNext_64Guest": "Microsoft Windows Server 2022 (64-bit)",
+	"windows2022srvNext_64Guest": "Microsoft Windows Server 2025 (64-bit)",
+	"windows9_64Guest":           "Microsoft Windows 10 (64-bit)",
+	"windows11_64Guest":          "Microsoft Windows 11 (64-bit)",
+	"ubuntu64Guest":              "Ubuntu Linux (64-bit)",
+	"debian11_64Guest":           "Debian GNU/Linux 11 (64-bit)",
+	"debian12_64Guest":           "Debian GNU/Linux 12 (64-bit)",
+	"rhel7_64Guest":              "Red Hat Enterprise Linux 7 (64-bit)",
+	"rhel8_64Guest":              "Red Hat Enterprise Linux 8 (64-bit)",
+	"rhel9_64Guest":              "Red Hat Enterprise Linux 9 (64-bit)",
+	"sles15_64Guest":             "SUSE Linux Enterprise 15 (64-bit)",
+	"other4xLinux64Guest":        "Other 4.x or later Linux (64-bit)",
+	"otherGuest64":               "Other (64-bit)",
+}
+
+// GuestOSFullName returns the name the API reports for a vSphere guest OS
+// identifier.
+func GuestOSFullName(guestOsId string) (string, bool) {
+	name, ok := guestOSNames[guestOsId]
+	return name, ok
+}
+
+// GuestOSIdFromName returns the vSphere guest OS identifier for a name
+// reported by the API. Names are matched case-insensitively.
+func GuestOSIdFromName(fullName string) (string, bool) {
+	for guestOsId, name := range guestOSNames {
+		if strings.EqualFold(name, fullName) {
+			return guestOsId, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeGuestOSId returns the vSphere identifier for a guest OS given
+// either its identifier or its name. Unknown values are returned unchanged
+// so guest OSes missing from the mapping can still be used.
+func NormalizeGuestOSId(guestOS string) string {
+	if guestOsId, ok := GuestOSIdFromName(guestOS); ok {
+		return guestOsId
+	}
+	return guestOS
+}
+
+// SameGuestOS reports whether a and b, each an identifier or a name, refer
+// to the same guest OS.
+func SameGuestOS(a, b string) bool {
+	return NormalizeGuestOSId(a) == NormalizeGuestOSId(b)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuestOSFullName(t *testing.T) {
+	// When
+	name, ok := GuestOSFullName("windows2019srv_64Guest")
+	_, unknown := GuestOSFullName("windows2000Guest")
+
+	// Then
+	assert.True(t, ok)
+	assert.Equal(t, "Microsoft Windows Server 2019 (64-bit)", name)
+	assert.False(t, unknown, "expected no name for an unmapped ID")
+}
+
+func TestGuestOSIdFromName(t *testing.T) {
+	// When
+	guestOsId, ok := GuestOSIdFromName("microsoft windows server 2022 (64-bit)")
+	_, unknown := GuestOSIdFromName("Microsoft Windows 2000")
+
+	// Then
+	assert.True(t, ok)
+	assert.Equal(t, "windows2019srvNext_64Guest", guestOsId)
+	assert.False(t, unknown, "expected no ID for an unmapped name")
+}
+
+func TestGuestOSNames_AreUnique(t *testing.T) {
+	// Given
+	seen := make(map[string]string)
+
+	// Then
+	for guestOsId, name := range guestOSNames {
+		other, duplicate := seen[name]
+		assert.False(t, duplicate, "%s and %s share the name %s", guestOsId, other, name)
+		seen[name] = guestOsId
+
+		roundTrip, ok := GuestOSIdFromName(name)
+		assert.True(t, ok)
+		assert.Equal(t, guestOsId, roundTrip, "%s doesn't round trip", guestOsId)
+	}
+}
+
+func TestSameGuestOS(t *testing.T) {
+	assert.True(t, SameGuestOS("windows2019srv_64Guest", "windows2019srv_64Guest"))
+	assert.True(t, SameGuestOS("windows2019srv_64Guest", "Microsoft Windows Server 2019 (64-bit)"))
+	assert.True(t, SameGuestOS("Ubuntu Linux (64-bit)", "ubuntu64Guest"))
+	assert.True(t, SameGuestOS("customGuest", "customGuest"))
+	assert.False(t, SameGuestOS("windows2019srv_64Guest", "windows2019srvNext_64Guest"))
+	assert.False(t, SameGuestOS("windows2019srv_64Guest", "Microsoft Windows Server 2022 (64-bit)"))
+}
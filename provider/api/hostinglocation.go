@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// HostingLocationLimits are the memory sizes a hosting location accepts.
+type HostingLocationLimits struct {
+	HostingLocationId string `json:"hostingLocationId"`
+	MinMemoryMb       int    `json:"minMemoryMb"`
+	MaxMemoryMb       int    `json:"maxMemoryMb"`
+	MemoryIncrementMb int    `json:"memoryIncrementMb"`
+}
+
+// CheckMemory returns an error describing why memoryMb isn't allowed, or nil
+// if it is. Zero limits aren't enforced.
+func (l HostingLocationLimits) CheckMemory(memoryMb int) error {
+	if l.MinMemoryMb > 0 && memoryMb < l.MinMemoryMb {
+		return fmt.Errorf("memory must be at least %d MB at %s, got: %d MB", l.MinMemoryMb, l.HostingLocationId, memoryMb)
+	}
+	if l.MaxMemoryMb > 0 && memoryMb > l.MaxMemoryMb {
+		return fmt.Errorf("memory must be at most %d MB at %s, got: %d MB", l.MaxMemoryMb, l.HostingLocationId, memoryMb)
+	}
+	if l.MemoryIncrementMb > 0 && memoryMb%l.MemoryIncrementMb != 0 {
+		return fmt.Errorf("memory must be a multiple of %d MB at %s, got: %d MB", l.MemoryIncrementMb, l.HostingLocationId, memoryMb)
+	}
+	return nil
+}
+
+// GetHostingLocationLimits returns the memory limits of a hosting location.
+// They rarely change, so they are cached like detailed VM lookups.
+func (c *Client) GetHostingLocationLimits(ctx context.Context, hostingLocationID string) (HostingLocationLimits, error) {
+	return c.limitsCache.get(hostingLocationID, c.CacheTTL, func() (HostingLocationLimits, error) {
+		return c.fetchHostingLocationLimits(ctx, hostingLocationID)
+	})
+}
+
+func (c *Client) fetchHostingLocationLimits(ctx context.Context, hostingLocationID string) (HostingLocationLimits, error) {
+	endpoint := fmt.Sprintf("/api/HostingLocation/Limits/%s", hostingLocationID)
+	resp, err := c.apiRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return HostingLocationLimits{}, fmt.Errorf("error getting limits of hosting location %s: %w", hostingLocationID, err)
+	}
+	defer resp.Body.Close()
+
+	var limits HostingLocationLimits
+	if err := json.NewDecoder(resp.Body).Decode(&limits); err != nil {
+		return HostingLocationLimits{}, fmt.Errorf("error decoding JSON response: %w", err)
+	}
+	if limits.HostingLocationId == "" {
+		limits.HostingLocationId = hostingLocationID
+	}
+
+	return limits, nil
+}
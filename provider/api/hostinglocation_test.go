@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHostingLocationLimits(t *testing.T) {
+	// Counter to track the number of limits requests
+	var getLimitsCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/HostingLocation/Limits/{HostingLocationId}
+		if r.Method == "GET" && r.URL.Path == "/api/HostingLocation/Limits/vcchcres" {
+			getLimitsCalls++
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"hostingLocationId": "vcchcres", "minMemoryMb": 512, "maxMemoryMb": 1048576, "memoryIncrementMb": 256}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+	client.CacheTTL = time.Minute
+
+	// When
+	limits, err := client.GetHostingLocationLimits(context.Background(), "vcchcres")
+	_, _ = client.GetHostingLocationLimits(context.Background(), "vcchcres")
+
+	// Then
+	assert.NoError(t, err, "expected no error from GetHostingLocationLimits")
+	assert.Equal(t, HostingLocationLimits{
+		HostingLocationId: "vcchcres",
+		MinMemoryMb:       512,
+		MaxMemoryMb:       1048576,
+		MemoryIncrementMb: 256,
+	}, limits, "Limits mismatch")
+	assert.Equal(t, 1, getLimitsCalls, "expected the limits to be cached")
+}
+
+func TestGetHostingLocationLimits_UnknownLocation(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.GetHostingLocationLimits(context.Background(), "vcnowhere")
+
+	// Then
+	assert.ErrorContains(t, err, "vcnowhere")
+}
+
+func TestHostingLocationLimits_CheckMemory(t *testing.T) {
+	// Given
+	limits := HostingLocationLimits{HostingLocationId: "vcchcres", MinMemoryMb: 512, MaxMemoryMb: 8192, MemoryIncrementMb: 256}
+
+	// Then
+	assert.NoError(t, limits.CheckMemory(512))
+	assert.NoError(t, limits.CheckMemory(1536))
+	assert.ErrorContains(t, limits.CheckMemory(256), "at least 512 MB")
+	assert.ErrorContains(t, limits.CheckMemory(16384), "at most 8192 MB")
+	assert.ErrorContains(t, limits.CheckMemory(1000), "multiple of 256 MB")
+	assert.NoError(t, HostingLocationLimits{}.CheckMemory(1000), "expected zero limits to allow anything")
+}
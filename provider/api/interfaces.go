@@ -1,10 +1,12 @@
 package api
 
+import "context"
+
 type VirtualMachineLookup interface {
-    GetVMByName(vmName string, clientId int) (string, error)
+	GetVMByName(ctx context.Context, vmName string, clientId int) (string, error)
 }
 
 type DiskManager interface {
-    CreateAdditionalDisk(vmID string, disk VirtualDisk) error
-    GetAdditionalDisk(vmID string, diskID string) (*VirtualDisk, error)
+	CreateAdditionalDisk(ctx context.Context, vmID string, disk VirtualDisk) error
+	GetVMDisk(ctx context.Context, vmID string, diskID string) (*VirtualDisk, error)
 }
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// keyedLock serialises work per key, e.g. all mutating operations against one
+// VM. The zero value is ready to use.
+type keyedLock struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLockEntry
+}
+
+// keyedLockEntry is the lock for one key. refs counts the callers holding or
+// waiting for it, so the entry can be dropped once none are left.
+type keyedLockEntry struct {
+	ch   chan struct{}
+	refs int
+}
+
+// lock blocks until key is free or ctx is done. The returned function
+// releases the lock.
+func (k *keyedLock) lock(ctx context.Context, key string) (func(), error) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLockEntry)
+	}
+	entry, ok := k.locks[key]
+	if !ok {
+		entry = &keyedLockEntry{ch: make(chan struct{}, 1)}
+		k.locks[key] = entry
+	}
+	entry.refs++
+	k.mu.Unlock()
+
+	select {
+	case entry.ch <- struct{}{}:
+		return func() {
+			<-entry.ch
+			k.release(key, entry)
+		}, nil
+	case <-ctx.Done():
+		k.release(key, entry)
+		return nil, ctx.Err()
+	}
+}
+
+// release drops a reference to the entry for key, deleting it when it was the
+// last one.
+func (k *keyedLock) release(key string, entry *keyedLockEntry) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entry.refs--
+	if entry.refs == 0 {
+		delete(k.locks, key)
+	}
+}
+
+// lockVM serialises mutating operations against a single VM so that, for
+// example, parallel disk additions can each identify their own new disk.
+func (c *Client) lockVM(ctx context.Context, vmID string) (func(), error) {
+	return c.vmLocks.lock(ctx, vmID)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedLock(t *testing.T) {
+	// Given
+	var locks keyedLock
+	unlock, err := locks.lock(context.Background(), "vm-1")
+	assert.NoError(t, err)
+
+	// When
+	otherUnlock, otherErr := locks.lock(context.Background(), "vm-2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, sameErr := locks.lock(ctx, "vm-1")
+
+	// Then
+	assert.NoError(t, otherErr, "expected a different key to lock independently")
+	assert.ErrorIs(t, sameErr, context.DeadlineExceeded, "expected the same key to block")
+
+	unlock()
+	otherUnlock()
+
+	relock, err := locks.lock(context.Background(), "vm-1")
+	assert.NoError(t, err, "expected the key to be free after unlock")
+	relock()
+}
+
+func TestKeyedLock_DeletesUnusedKeys(t *testing.T) {
+	// Given
+	var locks keyedLock
+	unlock, err := locks.lock(context.Background(), "vm-1")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, waitErr := locks.lock(ctx, "vm-1")
+
+	// When
+	heldKeys := len(locks.locks)
+	unlock()
+
+	// Then
+	assert.ErrorIs(t, waitErr, context.DeadlineExceeded)
+	assert.Equal(t, 1, heldKeys, "expected the held key to be kept after a waiter gave up")
+	assert.Empty(t, locks.locks, "expected the key to be deleted once unlocked")
+}
+
+func TestKeyedLock_Concurrent(t *testing.T) {
+	// Given
+	var locks keyedLock
+	var wg sync.WaitGroup
+	var holders, maxHolders int32
+
+	// When
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := locks.lock(context.Background(), "vm-1")
+			if !assert.NoError(t, err) {
+				return
+			}
+			n := atomic.AddInt32(&holders, 1)
+			for {
+				max := atomic.LoadInt32(&maxHolders)
+				if n <= max || atomic.CompareAndSwapInt32(&maxHolders, max, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	assert.Equal(t, int32(1), maxHolders, "expected one holder at a time")
+	assert.Empty(t, locks.locks, "expected every key to be deleted once unlocked")
+}
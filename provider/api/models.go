@@ -1,6 +1,9 @@
 package api
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type VirtualMachine struct {
 	ClientId            int                    `json:"clientId"`
@@ -8,7 +11,9 @@ type VirtualMachine struct {
 	Template            string                 `json:"template"`
 	GuestOsId           string                 `json:"guestOsId"`
 	Cores               int                    `json:"cores"`
+	CoresPerSocket      int                    `json:"coresPerSocket,omitempty"`
 	MemorySize          int                    `json:"memorySize"`
+	MemoryMb            int                    `json:"memoryMb,omitempty"`
 	OperatingSystemDisk VirtualDisk            `json:"operatingSystemDisk"`
 	IsoFile             string                 `json:"isoFile,omitempty"`
 	QuoteItem           map[string]interface{} `json:"quoteItem"`
@@ -17,6 +22,44 @@ type VirtualMachine struct {
 	Specification       Specification          `json:"specification"`
 	MountedISO          *string                `json:"mountedISO"`
 	BackupType          string                 `json:"backupType,omitempty"`
+	HasSnapshot         bool                   `json:"hasSnapshot,omitempty"`
+	Licenses            []License              `json:"licenses,omitempty"`
+	// GuestOS is the guest OS name the detailed endpoint reports in place
+	// of GuestOsId, see GuestOSFullName.
+	GuestOS string `json:"guestOS,omitempty"`
+	// Annotation holds the VM's notes and tags, see EncodeAnnotation.
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// License is a guest OS license offered for a VM. Offered licenses that
+// aren't assigned have a LicenseId of 0 and no key.
+type License struct {
+	LicenseId  int    `json:"licenseId"`
+	Name       string `json:"name"`
+	LicenseKey string `json:"licenseKey"`
+}
+
+func (l License) Assigned() bool {
+	return l.LicenseId != 0
+}
+
+// AssignedLicense returns the license the VM consumes, or nil if it has
+// none.
+func (vm VirtualMachine) AssignedLicense() *License {
+	for _, license := range vm.Licenses {
+		if license.Assigned() {
+			return &license
+		}
+	}
+	return nil
+}
+
+// VirtualResourceSummary is an entry in GET /api/client/virtualresources/{clientId}.
+type VirtualResourceSummary struct {
+	Id              int    `json:"id"`
+	ClientId        int    `json:"clientId"`
+	Name            string `json:"name"`
+	HostingLocation string `json:"hostingLocation"`
 }
 
 type HostingLocation struct {
@@ -25,17 +68,54 @@ type HostingLocation struct {
 	DefaultNetwork string `json:"defaultNetwork"`
 }
 
+// Power states reported in Specification.PowerState.
+const (
+	PowerStateOn  = "On"
+	PowerStateOff = "Off"
+)
+
 type Specification struct {
 	HealthState       string          `json:"healthState"`
 	PowerState        string          `json:"powerState"`
 	Cores             int             `json:"cores"`
 	Sockets           int             `json:"sockets"`
 	MemoryGb          int             `json:"memoryGb"`
+	MemoryMb          int             `json:"memoryMb"`
 	MoRef             string          `json:"moRef"`
 	VirtualDisks      []VirtualDisk   `json:"virtualDisks"`
 	NetworkDevices    []NetworkDevice `json:"networkDevices"`
 	HostingLocationId string          `json:"hostingLocationId"`
 	BackupType        string          `json:"backupType"`
+	// InstantRecoveryDisks are disks mounted from restore points.
+	InstantRecoveryDisks []InstantRecoveryDisk `json:"instantRecoveryDisks"`
+}
+
+// TotalCores is the VM's vCPU count, Cores per socket times Sockets.
+func (s Specification) TotalCores() int {
+	if s.Sockets == 0 {
+		return s.Cores
+	}
+	return s.Cores * s.Sockets
+}
+
+// MemorySizeMb is the VM's memory in MB. Responses that only report whole
+// GB are converted.
+func (s Specification) MemorySizeMb() int {
+	if s.MemoryMb > 0 {
+		return s.MemoryMb
+	}
+	return s.MemoryGb * 1024
+}
+
+// HasMemoryMb reports whether the VM has memoryMb MB of memory. Responses
+// that only report whole GB, like the production detailed response, can't
+// tell sizes within a GB apart, so a size that rounds up or down to the
+// reported GB matches.
+func (s Specification) HasMemoryMb(memoryMb int) bool {
+	if s.MemoryMb > 0 {
+		return s.MemoryMb == memoryMb
+	}
+	return memoryMb/1024 == s.MemoryGb || (memoryMb+1023)/1024 == s.MemoryGb
 }
 
 type NetworkDevice struct {
@@ -80,11 +160,63 @@ func (vd *VirtualDisk) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ProvisioningResponse is the body returned by POST /api/Provisioning/VirtualMachine.
+// Either field may be absent depending on how the VM is provisioned.
+type ProvisioningResponse struct {
+	VirtualResourceId json.Number `json:"virtualResourceId,omitempty"`
+	TaskId            string      `json:"taskId,omitempty"`
+}
+
+// OperationResponse is the body returned by virtual resource operations such
+// as DeleteDisk. The API may return an empty body, a task to track, or an
+// explicit failure.
+type OperationResponse struct {
+	TaskId  string `json:"taskId,omitempty"`
+	Success *bool  `json:"success,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Task struct {
+	Id                string      `json:"id"`
+	Status            string      `json:"status"`
+	Message           string      `json:"message"`
+	VirtualResourceId json.Number `json:"virtualResourceId,omitempty"`
+}
+
 type PowerOperationPayload struct {
 	VirtualResourceId string `json:"VirtualResourceId"`
 	Operation         string `json:"Operation"`
 }
 
+type RenameVMPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Name              string `json:"name"`
+}
+
+type UpdateCPUPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Cores             int    `json:"cores"`
+	CoresPerSocket    int    `json:"coresPerSocket"`
+}
+
+type UpdateMemoryPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	MemoryMb          int    `json:"memoryMb"`
+}
+
+type ChangeGuestOSPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	GuestOsId         string `json:"guestOsId"`
+}
+
+// MigrateVMPayload moves a VM to another hosting location. NetworkMapping
+// maps each network the VM's NICs are on to a network at the target.
+type MigrateVMPayload struct {
+	VirtualResourceId string            `json:"VirtualResourceId"`
+	HostingLocationId string            `json:"hostingLocationId"`
+	NetworkMapping    map[string]string `json:"networkMapping"`
+}
+
 type DeleteVMOperationPayload struct {
 	VirtualResourceId string `json:"VirtualResourceId"`
 	CheckToken        string `json:"CheckToken"`
@@ -108,3 +240,75 @@ type DeleteDiskPayload struct {
 	DiskUUID          string `json:"diskUUID"`
 	Description       string `json:"description"`
 }
+
+// Snapshot is an entry in GET /api/VirtualResource/Snapshots/{vmId}.
+type Snapshot struct {
+	Id            string    `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	CreatedOn     time.Time `json:"createdOn"`
+	IncludeMemory bool      `json:"includeMemory"`
+	Quiesce       bool      `json:"quiesce"`
+}
+
+type CreateSnapshotPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IncludeMemory     bool   `json:"includeMemory"`
+	Quiesce           bool   `json:"quiesce"`
+}
+
+type SnapshotOperationPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	SnapshotId        string `json:"snapshotId"`
+}
+
+// Restore types accepted by POST /api/virtualresource/Restore.
+const (
+	RestoreTypeFull            = "Full"
+	RestoreTypeInstantRecovery = "InstantRecovery"
+)
+
+// RestorePoint is an entry in GET /api/VirtualResource/RestorePoints/{vmId}.
+type RestorePoint struct {
+	Id        string    `json:"id"`
+	CreatedOn time.Time `json:"createdOn"`
+	JobName   string    `json:"jobName"`
+	// Type is Full or Increment.
+	Type string `json:"type"`
+}
+
+type InstantRecoveryDisk struct {
+	Name  string `json:"name"`
+	MoRef string `json:"moRef"`
+	// Capacity is reported as a float, like the detailed virtual disks.
+	Capacity       float64 `json:"capacity"`
+	RestorePointId string  `json:"restorePointId"`
+}
+
+type RestorePayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	RestorePointId    string `json:"restorePointId"`
+	RestoreType       string `json:"restoreType"`
+}
+
+type UnmountInstantRecoveryPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	RestorePointId    string `json:"restorePointId"`
+}
+
+type AssignLicensePayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Name              string `json:"name"`
+}
+
+type RemoveLicensePayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	LicenseId         int    `json:"licenseId"`
+}
+
+type UpdateAnnotationPayload struct {
+	VirtualResourceId string `json:"VirtualResourceId"`
+	Annotation        string `json:"annotation"`
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+func (c *Client) GetTask(ctx context.Context, taskID string) (Task, error) {
+	endpoint := fmt.Sprintf("/api/Task/%s", taskID)
+	resp, err := c.apiRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return Task{}, err
+	}
+	defer resp.Body.Close()
+
+	var task Task
+	err = json.NewDecoder(resp.Body).Decode(&task)
+	if err != nil {
+		return Task{}, fmt.Errorf("error decoding JSON response: %w", err)
+	}
+
+	return task, nil
+}
+
+// WaitForTask polls a task until it reaches a terminal status, returning an
+// error if the task failed.
+func (c *Client) WaitForTask(ctx context.Context, taskID string) (Task, error) {
+	var task Task
+	err := c.waiter().Wait(ctx, fmt.Sprintf("task %s", taskID), func(ctx context.Context) (bool, string, error) {
+		var err error
+		task, err = c.GetTask(ctx, taskID)
+		if err != nil {
+			return false, "", err
+		}
+
+		switch strings.ToLower(task.Status) {
+		case "completed", "complete", "succeeded", "success":
+			return true, task.Status, nil
+		case "failed", "error", "cancelled", "canceled":
+			return false, task.Status, fmt.Errorf("task finished with status %s: %s", task.Status, task.Message)
+		}
+		return false, task.Status, nil
+	})
+
+	return task, err
+}
+
+// checkOperationResponse reads the body of an operation request and returns
+// an error if the API reported a failure, either directly or through the task
+// it started.
+func (c *Client) checkOperationResponse(ctx context.Context, resp *http.Response) error {
+	_, err := c.waitForOperation(ctx, resp)
+	return err
+}
+
+// waitForOperation is checkOperationResponse for callers that need the task
+// the operation started. The task is empty if it didn't start one.
+func (c *Client) waitForOperation(ctx context.Context, resp *http.Response) (Task, error) {
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Task{}, fmt.Errorf("error reading operation response: %w", err)
+	}
+
+	// Empty or non-JSON bodies carry no status, so the caller's own polling
+	// decides whether the operation worked.
+	var operation OperationResponse
+	if len(bytes.TrimSpace(bodyBytes)) == 0 || json.Unmarshal(bodyBytes, &operation) != nil {
+		return Task{}, nil
+	}
+
+	if operation.Success != nil && !*operation.Success {
+		return Task{}, fmt.Errorf("API reported failure: %s", operation.Message)
+	}
+
+	if operation.TaskId == "" {
+		return Task{}, nil
+	}
+
+	task, err := c.WaitForTask(ctx, operation.TaskId)
+	if task.Id == "" {
+		task.Id = operation.TaskId
+	}
+	return task, err
+}
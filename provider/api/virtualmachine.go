@@ -1,81 +1,166 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"time"
+	"strconv"
 )
 
-func (c *Client) CreateVM(vm VirtualMachine) (string, error) {
+func (c *Client) CreateVM(ctx context.Context, vm VirtualMachine) (string, error) {
+	// Names are the only fallback identity the API gives us, so refuse to
+	// create a second VM with the same name for the client.
+	existingID, err := c.GetVMByName(ctx, vm.Name, vm.ClientId)
+	if err == nil {
+		return "", fmt.Errorf("a VM named %s already exists for client %d (id %s)", vm.Name, vm.ClientId, existingID)
+	} else if !errors.Is(err, ErrNotFound) {
+		return "", fmt.Errorf("error checking for existing VM %s: %w", vm.Name, err)
+	}
+
 	endpoint := "/api/Provisioning/VirtualMachine"
-	resp, err := c.apiRequest("POST", endpoint, vm)
+	resp, err := c.apiRequest(ctx, "POST", endpoint, vm)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
+	c.invalidateVirtualResources()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("error response from API: %s - %s", resp.Status, string(bodyBytes))
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading provisioning response: %w", err)
 	}
 
-	// Polling parameters
-	timeout := 2 * time.Minute
-	interval := 5 * time.Second
-	startTime := time.Now()
+	// The provisioning endpoint may return an empty body, so a body that
+	// doesn't decode is treated the same as one without identifiers.
+	var provisioning ProvisioningResponse
+	if len(bytes.TrimSpace(bodyBytes)) > 0 {
+		_ = json.Unmarshal(bodyBytes, &provisioning)
+	}
 
-	for {
-		if time.Since(startTime) >= timeout {
-			return "", fmt.Errorf("timed out waiting for VM %s to become available", vm.Name)
+	vmID := provisioning.VirtualResourceId.String()
+	if provisioning.TaskId != "" {
+		task, err := c.WaitForTask(ctx, provisioning.TaskId)
+		if err != nil {
+			return "", fmt.Errorf("error provisioning VM %s: %w", vm.Name, err)
+		}
+		if vmID == "" {
+			vmID = task.VirtualResourceId.String()
 		}
+	}
 
-		vmID, err := c.GetVMByName(vm.Name, vm.ClientId)
-		if err == nil {
-			return vmID, nil
+	if vmID == "" {
+		// The list was invalidated by the POST above; after that, polling
+		// reuses the cached list so concurrent creates share one fetch per
+		// CacheTTL.
+		err = c.waiter().Wait(ctx, fmt.Sprintf("provisioning VM %s", vm.Name), func(ctx context.Context) (bool, string, error) {
+			vmID, err = c.GetVMByName(ctx, vm.Name, vm.ClientId)
+			if errors.Is(err, ErrNotFound) {
+				return false, "not yet listed", nil
+			} else if err != nil {
+				return false, "", err
+			}
+			return true, "listed", nil
+		})
+		if err != nil {
+			return "", err
 		}
+	}
 
-		time.Sleep(interval)
+	// A VM can be listed before the detailed endpoint knows about it. Only
+	// a 404 or a transient failure is retried.
+	waiter := c.waiter()
+	if waiter.Timeout <= 0 || waiter.Timeout > DefaultVisibilityTimeout {
+		waiter.Timeout = DefaultVisibilityTimeout
 	}
+	err = waiter.Wait(ctx, fmt.Sprintf("provisioning VM %s", vm.Name), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		_, err := c.GetVMDetailedByID(ctx, vmID)
+		if errors.Is(err, ErrNotFound) {
+			return false, fmt.Sprintf("VM %s not yet available", vmID), nil
+		} else if err != nil {
+			return false, "", err
+		}
+		return true, "available", nil
+	})
+	return vmID, err
 }
 
-func (c *Client) GetVMByName(vmName string, clientId int) (string, error) {
-	endpoint := fmt.Sprintf("/api/client/virtualresources/%d", clientId)
-	resp, err := c.apiRequest("GET", endpoint, nil)
+func (c *Client) GetVMByName(ctx context.Context, vmName string, clientId int) (string, error) {
+	vms, err := c.ListVirtualResources(ctx, clientId)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("error response from API: %s - %s", resp.Status, string(bodyBytes))
+	for _, vm := range vms {
+		if vm.Name == vmName {
+			return fmt.Sprintf("%d", vm.Id), nil
+		}
 	}
 
-	var vms []struct {
-		Id              int    `json:"id"`
-		Name            string `json:"name"`
-		HostingLocation string `json:"hostingLocation"`
-	}
+	return "", fmt.Errorf("VM with name %s: %w", vmName, ErrNotFound)
+}
 
-	err = json.NewDecoder(resp.Body).Decode(&vms)
+// VMExists reports whether vmID is in the client's virtual resource list.
+func (c *Client) VMExists(ctx context.Context, vmID string, clientId int) (bool, error) {
+	vms, err := c.ListVirtualResources(ctx, clientId)
 	if err != nil {
-		return "", fmt.Errorf("error decoding JSON response: %w", err)
+		return false, err
 	}
 
 	for _, vm := range vms {
-		if vm.Name == vmName {
-			return fmt.Sprintf("%d", vm.Id), nil
+		if fmt.Sprintf("%d", vm.Id) == vmID {
+			return true, nil
 		}
 	}
 
-	return "", fmt.Errorf("VM with name %s not found", vmName)
+	return false, nil
 }
 
-func (c *Client) GetVMDetailedByID(vmID string) (VirtualMachine, error) {
+// ListVirtualResources returns a summary of every virtual resource owned by
+// the client. The list is cached for CacheTTL so name lookups and existence
+// checks made during a run share a single download.
+func (c *Client) ListVirtualResources(ctx context.Context, clientId int) ([]VirtualResourceSummary, error) {
+	vms, err := c.listCache.get(strconv.Itoa(clientId), c.CacheTTL, func() ([]VirtualResourceSummary, error) {
+		endpoint := fmt.Sprintf("/api/client/virtualresources/%d", clientId)
+		resp, err := c.apiRequest(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var vms []VirtualResourceSummary
+		err = json.NewDecoder(resp.Body).Decode(&vms)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding JSON response: %w", err)
+		}
+
+		return vms, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]VirtualResourceSummary(nil), vms...), nil
+}
+
+func (c *Client) GetVMDetailedByID(ctx context.Context, vmID string) (VirtualMachine, error) {
+	vm, err := c.detailedCache.get(vmID, c.CacheTTL, func() (VirtualMachine, error) {
+		return c.fetchVMDetailed(ctx, vmID)
+	})
+	if err != nil {
+		return VirtualMachine{}, err
+	}
+
+	return copyVM(vm), nil
+}
+
+func (c *Client) fetchVMDetailed(ctx context.Context, vmID string) (VirtualMachine, error) {
 	endpoint := fmt.Sprintf("/api/VirtualResource/Detailed/%s", vmID)
-	resp, err := c.apiRequest("GET", endpoint, nil)
+	resp, err := c.apiRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return VirtualMachine{}, err
 	}
@@ -120,39 +205,189 @@ func (c *Client) GetVMDetailedByID(vmID string) (VirtualMachine, error) {
 	return vm, nil
 }
 
-func (c *Client) PowerOffVM(vmID string) error {
+// RenameVM renames a VM in place. Its ID doesn't change. As with CreateVM,
+// a name already used by another of the client's VMs is refused.
+func (c *Client) RenameVM(ctx context.Context, vmID string, clientId int, name string) error {
+	existingID, err := c.GetVMByName(ctx, name, clientId)
+	if err == nil && existingID != vmID {
+		return fmt.Errorf("a VM named %s already exists for client %d (id %s)", name, clientId, existingID)
+	} else if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("error checking for existing VM %s: %w", name, err)
+	}
+
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/Rename"
+	payload := RenameVMPayload{
+		VirtualResourceId: vmID,
+		Name:              name,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+	c.invalidateVirtualResources()
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("renaming VM %s to %s", vmID, name), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+		return vm.Name == name, fmt.Sprintf("named %s", vm.Name), nil
+	})
+}
+
+// UpdateCPU sets a VM's total vCPU count and how many cores each socket
+// has. vSphere only allows the topology to change, or CPUs to be removed,
+// while the VM is powered off.
+func (c *Client) UpdateCPU(ctx context.Context, vmID string, cores int, coresPerSocket int) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/UpdateCPU"
+	payload := UpdateCPUPayload{
+		VirtualResourceId: vmID,
+		Cores:             cores,
+		CoresPerSocket:    coresPerSocket,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return fmt.Errorf("error updating CPU of VM %s: %w", vmID, err)
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("VM %s to have %d cores", vmID, cores), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+		spec := vm.Specification
+		return spec.TotalCores() == cores && spec.Cores == coresPerSocket, fmt.Sprintf("%d sockets x %d cores", spec.Sockets, spec.Cores), nil
+	})
+}
+
+// UpdateMemory sets a VM's memory in MB. vSphere only allows memory to be
+// removed while the VM is powered off.
+func (c *Client) UpdateMemory(ctx context.Context, vmID string, memoryMb int) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/UpdateMemory"
+	payload := UpdateMemoryPayload{
+		VirtualResourceId: vmID,
+		MemoryMb:          memoryMb,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return fmt.Errorf("error updating memory of VM %s: %w", vmID, err)
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("VM %s to have %d MB of memory", vmID, memoryMb), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+		return vm.Specification.HasMemoryMb(memoryMb), fmt.Sprintf("%d MB", vm.Specification.MemorySizeMb()), nil
+	})
+}
+
+func (c *Client) PowerOffVM(ctx context.Context, vmID string) error {
+	return c.powerOperation(ctx, vmID, "off")
+}
+
+func (c *Client) PowerOnVM(ctx context.Context, vmID string) error {
+	return c.powerOperation(ctx, vmID, "on")
+}
+
+// WaitForPowerState waits for a VM's specification to report powerState,
+// PowerStateOn or PowerStateOff.
+func (c *Client) WaitForPowerState(ctx context.Context, vmID string, powerState string) error {
+	return c.waiter().Wait(ctx, fmt.Sprintf("VM %s to be powered %s", vmID, powerState), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+		return vm.Specification.PowerState == powerState, vm.Specification.PowerState, nil
+	})
+}
+
+func (c *Client) powerOperation(ctx context.Context, vmID string, operation string) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	endpoint := "/api/virtualresource/poweroperation"
 	payload := PowerOperationPayload{
 		VirtualResourceId: vmID,
-		Operation:         "off",
+		Operation:         operation,
 	}
 
-	resp, err := c.apiRequest("POST", endpoint, payload)
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	c.invalidateVM(vmID)
 
-	return nil
+	return c.checkOperationResponse(ctx, resp)
 }
 
-func (c *Client) DeleteVM(vmID string, moRef string) error {
+func (c *Client) DeleteVM(ctx context.Context, vmID string, moRef string) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	endpoint := "/api/virtualresource/delete"
 	payload := DeleteVMOperationPayload{
 		VirtualResourceId: vmID,
 		CheckToken:        moRef,
 	}
 
-	resp, err := c.apiRequest("POST", endpoint, payload)
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+	c.invalidateVirtualResources()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("error response from API: %s - %s", resp.Status, string(bodyBytes))
-	}
-
-	return nil
+	return c.checkOperationResponse(ctx, resp)
 }
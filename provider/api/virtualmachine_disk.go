@@ -1,12 +1,27 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
-func (c *Client) CreateAdditionalDisk(vmID string, disk VirtualDisk) error {
+// CreateAdditionalDisk adds a disk to a VM. CreateAdditionalDiskWithComparison
+// also works out which of the VM's disks is the new one.
+func (c *Client) CreateAdditionalDisk(ctx context.Context, vmID string, disk VirtualDisk) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
+	return c.addDisk(ctx, vmID, disk)
+}
+
+// addDisk is CreateAdditionalDisk for callers already holding the VM's lock.
+func (c *Client) addDisk(ctx context.Context, vmID string, disk VirtualDisk) error {
 	endpoint := "/api/virtualresource/AddDisk"
 	payload := CreateAdditionalDiskPayload{
 		VirtualResourceId: vmID,
@@ -14,74 +29,87 @@ func (c *Client) CreateAdditionalDisk(vmID string, disk VirtualDisk) error {
 		Size:              disk.Capacity,
 	}
 
-	resp, err := c.apiRequest("POST", endpoint, payload)
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	c.invalidateVM(vmID)
 
-	return nil
+	return c.checkOperationResponse(ctx, resp)
 }
 
-func (c *Client) CreateAdditionalDiskWithComparison(vmID string, disk VirtualDisk) (string, error) {
-	initialVM, err := c.GetVMDetailedByID(vmID)
+func (c *Client) CreateAdditionalDiskWithComparison(ctx context.Context, vmID string, disk VirtualDisk) (string, error) {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	c.invalidateVM(vmID)
+	initialVM, err := c.GetVMDetailedByID(ctx, vmID)
 	if err != nil {
 		return "", fmt.Errorf("error getting VM details before adding disk: %w", err)
 	}
 	initialDisks := initialVM.Specification.VirtualDisks
 
-	fmt.Println("Creating additional disk...")
-	err = c.CreateAdditionalDisk(vmID, disk)
+	tflog.Info(ctx, "creating additional disk", map[string]interface{}{"vm_id": vmID, "capacity": disk.Capacity})
+	err = c.addDisk(ctx, vmID, disk)
 	if err != nil {
 		return "", fmt.Errorf("error creating additional disk: %w", err)
 	}
 
-	// Polling parameters
-	timeout := 2 * time.Minute
-	interval := 5 * time.Second
-	startTime := time.Now()
-
-	for {
-		if time.Since(startTime) >= timeout {
-			return "", fmt.Errorf("timed out waiting for disk to be added to VM %s", vmID)
-		}
-
-		updatedVM, err := c.GetVMDetailedByID(vmID)
+	var newDiskMoRef string
+	err = c.waiter().Wait(ctx, fmt.Sprintf("adding disk to VM %s", vmID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		updatedVM, err := c.GetVMDetailedByID(ctx, vmID)
 		if err != nil {
-			fmt.Printf("Error getting VM details during polling: %v\n", err)
-		} else {
-			updatedDisks := updatedVM.Specification.VirtualDisks
-
-			newDiskMoRef := findNewDiskMoRef(initialDisks, updatedDisks)
-			if newDiskMoRef != "" {
-				fmt.Printf("New disk added with MoRef: %s\n", newDiskMoRef)
-				return newDiskMoRef, nil
-			}
+			return false, "", err
 		}
 
-		time.Sleep(interval)
+		newDiskMoRef = findNewDiskMoRef(initialDisks, updatedVM.Specification.VirtualDisks, disk)
+		if newDiskMoRef == "" {
+			return false, fmt.Sprintf("%d disks attached", len(updatedVM.Specification.VirtualDisks)), nil
+		}
+		return true, fmt.Sprintf("disk %s attached", newDiskMoRef), nil
+	})
+	if err != nil {
+		return "", err
 	}
+
+	tflog.Info(ctx, "additional disk created", map[string]interface{}{"vm_id": vmID, "disk_id": newDiskMoRef})
+	return newDiskMoRef, nil
 }
 
-func findNewDiskMoRef(initialDisks, updatedDisks []VirtualDisk) string {
+// findNewDiskMoRef returns the MoRef of a disk present in updatedDisks but not
+// in initialDisks that matches the requested size and tier, so a disk added
+// out of band is not mistaken for the one we asked for.
+func findNewDiskMoRef(initialDisks, updatedDisks []VirtualDisk, want VirtualDisk) string {
 	initialDiskMap := make(map[string]bool)
 	for _, disk := range initialDisks {
 		initialDiskMap[disk.MoRef] = true
 	}
 
 	for _, disk := range updatedDisks {
-		if _, found := initialDiskMap[disk.MoRef]; !found {
-			return disk.MoRef
+		if _, found := initialDiskMap[disk.MoRef]; found {
+			continue
+		}
+		if want.Capacity != 0 && disk.Capacity != want.Capacity {
+			continue
+		}
+		if want.StorageProfile != "" && disk.StorageProfile != want.StorageProfile {
+			continue
 		}
+		return disk.MoRef
 	}
 
 	return ""
 }
 
-func (c *Client) GetVMDisk(vmID string, diskID string) (*VirtualDisk, error) {
-	vm, err := c.GetVMDetailedByID(vmID)
+func (c *Client) GetVMDisk(ctx context.Context, vmID string, diskID string) (*VirtualDisk, error) {
+	vm, err := c.GetVMDetailedByID(ctx, vmID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get VM details: %v", err)
+		return nil, fmt.Errorf("failed to get VM details: %w", err)
 	}
 
 	for _, vmDisk := range vm.Specification.VirtualDisks {
@@ -90,10 +118,16 @@ func (c *Client) GetVMDisk(vmID string, diskID string) (*VirtualDisk, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("disk with MoRef %s not found in VM %s", diskID, vmID)
+	return nil, fmt.Errorf("disk with MoRef %s in VM %s: %w", diskID, vmID, ErrNotFound)
 }
 
-func (c *Client) ExtendVMDisk(vmID string, diskID string, newDiskSize int) error {
+func (c *Client) ExtendVMDisk(ctx context.Context, vmID string, diskID string, newDiskSize int) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	endpoint := "/api/VirtualResource/ExtendDisk"
 	payload := ExtendDiskPayload{
 		VirtualResourceId: vmID,
@@ -102,16 +136,34 @@ func (c *Client) ExtendVMDisk(vmID string, diskID string, newDiskSize int) error
 		Description:       "",
 	}
 
-	resp, err := c.apiRequest("POST", endpoint, payload)
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	c.invalidateVM(vmID)
 
-	return nil
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("extending disk %s on VM %s", diskID, vmID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		disk, err := c.GetVMDisk(ctx, vmID, diskID)
+		if err != nil {
+			return false, "", err
+		}
+		return disk.Capacity >= newDiskSize, fmt.Sprintf("capacity %d GB", disk.Capacity), nil
+	})
 }
 
-func (c *Client) DeleteVMDisk(vmID string, diskID string) error {
+func (c *Client) DeleteVMDisk(ctx context.Context, vmID string, diskID string) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	endpoint := "/api/virtualresource/DeleteDisk"
 	payload := DeleteDiskPayload{
 		VirtualResourceId: vmID,
@@ -119,11 +171,25 @@ func (c *Client) DeleteVMDisk(vmID string, diskID string) error {
 		Description:       "",
 	}
 
-	resp, err := c.apiRequest("POST", endpoint, payload)
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	c.invalidateVM(vmID)
 
-	return nil
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("deleting disk %s from VM %s", diskID, vmID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		_, err := c.GetVMDisk(ctx, vmID, diskID)
+		if errors.Is(err, ErrNotFound) {
+			return true, "detached", nil
+		} else if err != nil {
+			return false, "", err
+		}
+		return false, "attached", nil
+	})
 }
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -46,12 +48,37 @@ func TestCreateAdditionalDisk(t *testing.T) {
 		Capacity:       500,
 		StorageProfile: "vStorageT1",
 	}
-	err := client.CreateAdditionalDisk("92582", disk)
+	err := client.CreateAdditionalDisk(context.Background(), "92582", disk)
 
 	// Then
 	assert.NoError(t, err, "expected no error from CreateAdditionalDisk")
 }
 
+func TestCreateAdditionalDisk_WaitsForVMLock(t *testing.T) {
+	// Given
+	var addDiskCalls int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addDiskCalls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+	unlock, err := client.lockVM(context.Background(), "92582")
+	assert.NoError(t, err)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// When
+	err = client.CreateAdditionalDisk(ctx, "92582", VirtualDisk{Capacity: 500, StorageProfile: "vStorageT1"})
+
+	// Then
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "expected CreateAdditionalDisk to wait for the VM's lock")
+	assert.Equal(t, 0, addDiskCalls, "expected no disk to be added while the VM is locked")
+}
+
 func TestCreateAdditionalDiskWithComparison(t *testing.T) {
 	// Counter to track the number of GetVMDetailedByID calls
 	var getVMDetailedByIDCalls int
@@ -146,7 +173,7 @@ func TestCreateAdditionalDiskWithComparison(t *testing.T) {
 		Capacity:       500,
 		StorageProfile: "vStorageT1",
 	}
-	result, err := client.CreateAdditionalDiskWithComparison("12345", disk)
+	result, err := client.CreateAdditionalDiskWithComparison(context.Background(), "12345", disk)
 
 	// Then
 	assert.NoError(t, err, "expected no error from CreateAdditionalDiskWithComparison")
@@ -154,6 +181,39 @@ func TestCreateAdditionalDiskWithComparison(t *testing.T) {
 	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
 }
 
+func TestCreateAdditionalDiskWithComparison_FailsOnForbidden(t *testing.T) {
+	// Given
+	var detailedCalls int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/virtualresource/AddDisk":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345":
+			detailedCalls++
+			if detailedCalls > 1 {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 12345, "name": "DISKVM0000"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.CreateAdditionalDiskWithComparison(context.Background(), "12345", VirtualDisk{Capacity: 500})
+
+	// Then
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	assert.Equal(t, 2, detailedCalls, "expected the wait to stop at the first 403")
+}
+
 func TestFindNewDiskMoRef(t *testing.T) {
 	// Given
 	initialDisks := []VirtualDisk{
@@ -164,16 +224,38 @@ func TestFindNewDiskMoRef(t *testing.T) {
 	updatedDisks := []VirtualDisk{
 		{MoRef: "disk-100"},
 		{MoRef: "disk-101"},
-		{MoRef: "disk-102"},
+		{MoRef: "disk-102", Capacity: 50, StorageProfile: "vStorageT1"},
 	}
 
 	// When
-	result := findNewDiskMoRef(initialDisks, updatedDisks)
+	result := findNewDiskMoRef(initialDisks, updatedDisks, VirtualDisk{Capacity: 50, StorageProfile: "vStorageT1"})
 
 	// Then
 	assert.Equal(t, "disk-102", result)
 }
 
+func TestFindNewDiskMoRef_MatchesSizeAndTier(t *testing.T) {
+	// Given
+	initialDisks := []VirtualDisk{
+		{MoRef: "disk-100"},
+	}
+
+	// Two disks added in parallel, only one of which is ours
+	updatedDisks := []VirtualDisk{
+		{MoRef: "disk-100"},
+		{MoRef: "disk-101", Capacity: 50, StorageProfile: "vStorageT1"},
+		{MoRef: "disk-102", Capacity: 50, StorageProfile: "vStorageT2"},
+	}
+
+	// When
+	result := findNewDiskMoRef(initialDisks, updatedDisks, VirtualDisk{Capacity: 50, StorageProfile: "vStorageT2"})
+	missing := findNewDiskMoRef(initialDisks, updatedDisks, VirtualDisk{Capacity: 80, StorageProfile: "vStorageT2"})
+
+	// Then
+	assert.Equal(t, "disk-102", result)
+	assert.Equal(t, "", missing)
+}
+
 func TestGetAdditionalDisk(t *testing.T) {
 	// Given
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -217,7 +299,7 @@ func TestGetAdditionalDisk(t *testing.T) {
 	client := testClient(mockServer.URL)
 
 	// When
-	result, err := client.GetVMDisk("12345", "6000C29d-e3d1-85ce-af08-acf6bae05978")
+	result, err := client.GetVMDisk(context.Background(), "12345", "6000C29d-e3d1-85ce-af08-acf6bae05978")
 
 	// Assert
 	assert.NoError(t, err)
@@ -230,3 +312,90 @@ func TestGetAdditionalDisk(t *testing.T) {
 	assert.Equal(t, disk.StorageProfile, result.StorageProfile)
 
 }
+
+func TestGetVMDisk_VMNotFound(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.GetVMDisk(context.Background(), "12345", "6000C29d-e3d1-85ce-af08-acf6bae05978")
+
+	// Then
+	assert.ErrorIs(t, err, ErrNotFound, "expected a missing VM to be reported as not found")
+}
+
+func TestDeleteVMDisk(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/DeleteDisk
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/DeleteDisk" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			// Simulate the disk detaching after the 2nd call
+			getVMDetailedByIDCalls++
+			disks := []map[string]interface{}{
+				{"moRef": "6000C29d-e3d1-85ce-af08-acf6bae05978", "capacity": 100.0, "tier": "Performance"},
+			}
+			if getVMDetailedByIDCalls < 2 {
+				disks = append(disks, map[string]interface{}{"moRef": "disk-101", "capacity": 50.0, "tier": "Performance"})
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":            12345,
+				"specification": map[string]interface{}{"virtualDisks": disks},
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.DeleteVMDisk(context.Background(), "12345", "disk-101")
+
+	// Then
+	assert.NoError(t, err, "expected no error from DeleteVMDisk")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+}
+
+func TestDeleteVMDisk_APIFailure(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/DeleteDisk
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/DeleteDisk" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success": false, "message": "disk is locked by a backup job"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.DeleteVMDisk(context.Background(), "12345", "disk-101")
+
+	// Then
+	assert.ErrorContains(t, err, "disk is locked by a backup job")
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangeGuestOS sets the vSphere guest OS identifier of a VM, e.g.
+// windows2019srvNext_64Guest, and waits for the detailed endpoint to report
+// it. vSphere only allows this while the VM is powered off.
+func (c *Client) ChangeGuestOS(ctx context.Context, vmID string, guestOsId string) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/ChangeGuestOS"
+	payload := ChangeGuestOSPayload{
+		VirtualResourceId: vmID,
+		GuestOsId:         guestOsId,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return fmt.Errorf("error changing guest OS of VM %s to %s: %w", vmID, guestOsId, err)
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("VM %s to have guest OS %s", vmID, guestOsId), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+		return reportsGuestOS(vm, guestOsId), fmt.Sprintf("guest OS %s", vm.GuestOS), nil
+	})
+}
+
+// reportsGuestOS reports whether vm has the guest OS guestOsId. A name
+// missing from the mapping can't be compared, so it is taken as done, the
+// same way reads leave the configured guest OS alone for it.
+func reportsGuestOS(vm VirtualMachine, guestOsId string) bool {
+	if vm.GuestOsId != "" {
+		return SameGuestOS(vm.GuestOsId, guestOsId)
+	}
+	if reported, ok := GuestOSIdFromName(vm.GuestOS); ok {
+		return SameGuestOS(reported, guestOsId)
+	}
+	return true
+}
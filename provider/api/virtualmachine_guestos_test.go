@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeGuestOS(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+	var receivedPayload ChangeGuestOSPayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/ChangeGuestOS
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/ChangeGuestOS" {
+			json.NewDecoder(r.Body).Decode(&receivedPayload)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the new guest OS showing up on the 2nd call
+			getVMDetailedByIDCalls++
+			guestOS := "Microsoft Windows Server 2019 (64-bit)"
+			if getVMDetailedByIDCalls >= 2 {
+				guestOS = "Microsoft Windows Server 2022 (64-bit)"
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 12345, "guestOS": guestOS})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.ChangeGuestOS(context.Background(), "12345", "windows2019srvNext_64Guest")
+
+	// Then
+	assert.NoError(t, err, "expected no error from ChangeGuestOS")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+	assert.Equal(t, ChangeGuestOSPayload{
+		VirtualResourceId: "12345",
+		GuestOsId:         "windows2019srvNext_64Guest",
+	}, receivedPayload, "Payload mismatch")
+}
+
+func TestReportsGuestOS(t *testing.T) {
+	tests := []struct {
+		name string
+		vm   VirtualMachine
+		want bool
+	}{
+		{"identifier matches", VirtualMachine{GuestOsId: "windows2019srvNext_64Guest"}, true},
+		{"identifier differs", VirtualMachine{GuestOsId: "windows2019srv_64Guest"}, false},
+		{"name matches", VirtualMachine{GuestOS: "Microsoft Windows Server 2022 (64-bit)"}, true},
+		{"name differs", VirtualMachine{GuestOS: "Microsoft Windows Server 2019 (64-bit)"}, false},
+		{"name not in the mapping", VirtualMachine{GuestOS: "FreeBSD 14 (64-bit)"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			got := reportsGuestOS(tt.vm, "windows2019srvNext_64Guest")
+
+			// Then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestChangeGuestOS_PoweredOn(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/ChangeGuestOS
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/ChangeGuestOS" {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error": "VM must be powered off to change its guest OS"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.ChangeGuestOS(context.Background(), "12345", "windows2019srvNext_64Guest")
+
+	// Then
+	assert.ErrorContains(t, err, "must be powered off")
+}
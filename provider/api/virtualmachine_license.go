@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// ListLicenses returns the guest OS licenses offered for a VM, including the
+// one it consumes.
+func (c *Client) ListLicenses(ctx context.Context, vmID string) ([]License, error) {
+	vm, err := c.GetVMDetailedByID(ctx, vmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VM details: %w", err)
+	}
+
+	return vm.Licenses, nil
+}
+
+// AssignLicense makes a VM consume the named license, replacing any license
+// it already has, and waits for the assignment to show up.
+func (c *Client) AssignLicense(ctx context.Context, vmID string, name string) (*License, error) {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tflog.Info(ctx, "assigning license", map[string]interface{}{"vm_id": vmID, "license": name})
+	endpoint := "/api/virtualresource/AssignLicense"
+	payload := AssignLicensePayload{
+		VirtualResourceId: vmID,
+		Name:              name,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return nil, err
+	}
+
+	var assigned *License
+	err = c.waiter().Wait(ctx, fmt.Sprintf("assigning license %s to VM %s", name, vmID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+
+		assigned = vm.AssignedLicense()
+		if assigned == nil {
+			return false, "no license assigned", nil
+		}
+		return assigned.Name == name, fmt.Sprintf("license %s assigned", assigned.Name), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assigned, nil
+}
+
+// RemoveLicense stops a VM consuming a license and waits for it to be
+// released.
+func (c *Client) RemoveLicense(ctx context.Context, vmID string, licenseID int) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/RemoveLicense"
+	payload := RemoveLicensePayload{
+		VirtualResourceId: vmID,
+		LicenseId:         licenseID,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("removing license %d from VM %s", licenseID, vmID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+
+		if license := vm.AssignedLicense(); license != nil && license.LicenseId == licenseID {
+			return false, fmt.Sprintf("license %s assigned", license.Name), nil
+		}
+		return true, "removed", nil
+	})
+}
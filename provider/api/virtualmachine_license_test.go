@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// offeredLicenses returns the detailed licenses array with name assigned, or
+// nothing assigned if name is empty.
+func offeredLicenses(name string) []map[string]interface{} {
+	var licenses []map[string]interface{}
+	for _, offered := range []string{"Windows Server 2016", "Windows Server 2019", "Windows Server 2022"} {
+		license := map[string]interface{}{"licenseId": 0, "name": offered, "licenseKey": ""}
+		if offered == name {
+			license["licenseId"] = 4242
+			license["licenseKey"] = "AAAAA-BBBBB-CCCCC-DDDDD-EEEEE"
+		}
+		licenses = append(licenses, license)
+	}
+	return licenses
+}
+
+func TestAssignLicense(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+	var payload AssignLicensePayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/AssignLicense
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/AssignLicense" {
+			json.NewDecoder(r.Body).Decode(&payload)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the license being assigned on the 2nd call
+			getVMDetailedByIDCalls++
+			assigned := ""
+			if getVMDetailedByIDCalls >= 2 {
+				assigned = "Windows Server 2022"
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":       12345,
+				"licenses": offeredLicenses(assigned),
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	license, err := client.AssignLicense(context.Background(), "12345", "Windows Server 2022")
+
+	// Then
+	assert.NoError(t, err, "expected no error from AssignLicense")
+	assert.Equal(t, 4242, license.LicenseId, "License ID mismatch")
+	assert.Equal(t, "AAAAA-BBBBB-CCCCC-DDDDD-EEEEE", license.LicenseKey, "License key mismatch")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+	assert.Equal(t, AssignLicensePayload{VirtualResourceId: "12345", Name: "Windows Server 2022"}, payload, "Payload mismatch")
+}
+
+func TestRemoveLicense(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+	var payload RemoveLicensePayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/RemoveLicense
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/RemoveLicense" {
+			json.NewDecoder(r.Body).Decode(&payload)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the license being released after the 2nd call
+			getVMDetailedByIDCalls++
+			assigned := "Windows Server 2022"
+			if getVMDetailedByIDCalls >= 3 {
+				assigned = ""
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":       12345,
+				"licenses": offeredLicenses(assigned),
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.RemoveLicense(context.Background(), "12345", 4242)
+
+	// Then
+	assert.NoError(t, err, "expected no error from RemoveLicense")
+	assert.Equal(t, 3, getVMDetailedByIDCalls, "expected 3 calls to GetVMDetailedByID")
+	assert.Equal(t, RemoveLicensePayload{VirtualResourceId: "12345", LicenseId: 4242}, payload, "Payload mismatch")
+}
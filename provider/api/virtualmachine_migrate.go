@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// MigrateVM moves a VM to another hosting location, e.g. from Christchurch
+// to Auckland, reconnecting its NICs to the networks given by
+// networkMapping. It waits for the migration job to finish and the VM to
+// report the new location, and returns the job.
+func (c *Client) MigrateVM(ctx context.Context, vmID string, hostingLocationID string, networkMapping map[string]string) (Task, error) {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return Task{}, err
+	}
+	defer unlock()
+
+	tflog.Info(ctx, "migrating VM", map[string]interface{}{"vm_id": vmID, "hosting_location_id": hostingLocationID})
+	endpoint := "/api/virtualresource/Migrate"
+	payload := MigrateVMPayload{
+		VirtualResourceId: vmID,
+		HostingLocationId: hostingLocationID,
+		NetworkMapping:    networkMapping,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return Task{}, fmt.Errorf("error migrating VM %s to %s: %w", vmID, hostingLocationID, err)
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+	c.invalidateVirtualResources()
+
+	task, err := c.waitForOperation(ctx, resp)
+	if err != nil {
+		return Task{}, fmt.Errorf("error migrating VM %s to %s: %w", vmID, hostingLocationID, err)
+	}
+
+	err = c.waiter().Wait(ctx, fmt.Sprintf("VM %s to move to %s", vmID, hostingLocationID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+		return vm.Specification.HostingLocationId == hostingLocationID, fmt.Sprintf("at %s", vm.Specification.HostingLocationId), nil
+	})
+	if err != nil {
+		return Task{}, err
+	}
+
+	// The list reports the location too, so drop anything cached while the
+	// job ran.
+	c.invalidateVirtualResources()
+	return task, nil
+}
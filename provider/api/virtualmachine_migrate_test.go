@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateVM(t *testing.T) {
+	// Counters to track the number of GetTask and GetVMDetailedByID calls
+	var getTaskCalls, getVMDetailedByIDCalls int
+	var receivedPayload MigrateVMPayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/Migrate
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/Migrate" {
+			json.NewDecoder(r.Body).Decode(&receivedPayload)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"taskId": "task-9"}`))
+			return
+		}
+
+		// Handle GET /api/Task/{taskId}
+		if r.Method == "GET" && r.URL.Path == "/api/Task/task-9" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the migration finishing on the 2nd call
+			getTaskCalls++
+			status := "Running"
+			if getTaskCalls >= 2 {
+				status = "Completed"
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "task-9", "status": status})
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the new location showing up on the 2nd call
+			getVMDetailedByIDCalls++
+			hostingLocationId := "vcchcres"
+			if getVMDetailedByIDCalls >= 2 {
+				hostingLocationId = "vcaklres"
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":            12345,
+				"specification": map[string]interface{}{"hostingLocationId": hostingLocationId},
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	task, err := client.MigrateVM(context.Background(), "12345", "vcaklres", map[string]string{"CHC-CUST-SDC-WAN": "AKL-CUST-SDC-WAN"})
+
+	// Then
+	assert.NoError(t, err, "expected no error from MigrateVM")
+	assert.Equal(t, "task-9", task.Id, "Task ID mismatch")
+	assert.Equal(t, 2, getTaskCalls, "expected 2 calls to GetTask")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+	assert.Equal(t, MigrateVMPayload{
+		VirtualResourceId: "12345",
+		HostingLocationId: "vcaklres",
+		NetworkMapping:    map[string]string{"CHC-CUST-SDC-WAN": "AKL-CUST-SDC-WAN"},
+	}, receivedPayload, "Payload mismatch")
+}
+
+func TestMigrateVM_TaskFailed(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/Migrate
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/Migrate" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"taskId": "task-10"}`))
+			return
+		}
+
+		// Handle GET /api/Task/{taskId}
+		if r.Method == "GET" && r.URL.Path == "/api/Task/task-10" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": "task-10", "status": "Failed", "message": "insufficient capacity at target"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.MigrateVM(context.Background(), "12345", "vcaklres", nil)
+
+	// Then
+	assert.ErrorContains(t, err, "insufficient capacity at target")
+}
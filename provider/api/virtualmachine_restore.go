@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// ListRestorePoints lists the backups a VM can be restored from, oldest
+// first.
+func (c *Client) ListRestorePoints(ctx context.Context, vmID string) ([]RestorePoint, error) {
+	endpoint := fmt.Sprintf("/api/VirtualResource/RestorePoints/%s", vmID)
+	resp, err := c.apiRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var restorePoints []RestorePoint
+	err = json.NewDecoder(resp.Body).Decode(&restorePoints)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding JSON response: %w", err)
+	}
+
+	return restorePoints, nil
+}
+
+// RestoreVM restores a VM from a restore point, either by overwriting it
+// (RestoreTypeFull) or by mounting the backed up disks alongside its own
+// (RestoreTypeInstantRecovery). It waits for the restore job to finish and
+// returns it.
+func (c *Client) RestoreVM(ctx context.Context, vmID string, restorePointID string, restoreType string) (Task, error) {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return Task{}, err
+	}
+	defer unlock()
+
+	tflog.Info(ctx, "restoring VM", map[string]interface{}{"vm_id": vmID, "restore_point_id": restorePointID, "restore_type": restoreType})
+	endpoint := "/api/virtualresource/Restore"
+	payload := RestorePayload{
+		VirtualResourceId: vmID,
+		RestorePointId:    restorePointID,
+		RestoreType:       restoreType,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return Task{}, err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	task, err := c.waitForOperation(ctx, resp)
+	if err != nil {
+		return Task{}, fmt.Errorf("error restoring VM %s from %s: %w", vmID, restorePointID, err)
+	}
+
+	// Only the task says when a restore has finished, so one that can't be
+	// tracked is an error rather than assumed to be done.
+	if task.Id == "" {
+		return Task{}, fmt.Errorf("error restoring VM %s from %s: the API didn't return a task to track the restore", vmID, restorePointID)
+	}
+
+	// The job has finished, so drop anything cached while it ran.
+	c.invalidateVM(vmID)
+	return task, nil
+}
+
+// GetInstantRecoveryDisks returns the disks mounted on a VM from a restore
+// point, or a wrapped ErrNotFound if none are.
+func (c *Client) GetInstantRecoveryDisks(ctx context.Context, vmID string, restorePointID string) ([]InstantRecoveryDisk, error) {
+	vm, err := c.GetVMDetailedByID(ctx, vmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VM details: %w", err)
+	}
+
+	disks := instantRecoveryDisks(vm, restorePointID)
+	if len(disks) == 0 {
+		return nil, fmt.Errorf("instant recovery of %s on VM %s: %w", restorePointID, vmID, ErrNotFound)
+	}
+	return disks, nil
+}
+
+func instantRecoveryDisks(vm VirtualMachine, restorePointID string) []InstantRecoveryDisk {
+	var disks []InstantRecoveryDisk
+	for _, disk := range vm.Specification.InstantRecoveryDisks {
+		if disk.RestorePointId == restorePointID {
+			disks = append(disks, disk)
+		}
+	}
+	return disks
+}
+
+// WaitForInstantRecoveryDisks waits for the disks an instant recovery
+// mounted to show up on the VM, which can lag behind the restore task.
+func (c *Client) WaitForInstantRecoveryDisks(ctx context.Context, vmID string, restorePointID string) ([]InstantRecoveryDisk, error) {
+	var disks []InstantRecoveryDisk
+	err := c.waiter().Wait(ctx, fmt.Sprintf("mounting %s on VM %s", restorePointID, vmID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		vm, err := c.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to get VM details: %w", err)
+		}
+
+		disks = instantRecoveryDisks(vm, restorePointID)
+		if len(disks) == 0 {
+			return false, "not mounted", nil
+		}
+		return true, "mounted", nil
+	})
+	return disks, err
+}
+
+// UnmountInstantRecovery removes the disks an instant recovery mounted and
+// waits for them to be gone.
+func (c *Client) UnmountInstantRecovery(ctx context.Context, vmID string, restorePointID string) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/UnmountInstantRecovery"
+	payload := UnmountInstantRecoveryPayload{
+		VirtualResourceId: vmID,
+		RestorePointId:    restorePointID,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("unmounting %s from VM %s", restorePointID, vmID), func(ctx context.Context) (bool, string, error) {
+		c.invalidateVM(vmID)
+		_, err := c.GetInstantRecoveryDisks(ctx, vmID, restorePointID)
+		if errors.Is(err, ErrNotFound) {
+			return true, "unmounted", nil
+		} else if err != nil {
+			return false, "", err
+		}
+		return false, "mounted", nil
+	})
+}
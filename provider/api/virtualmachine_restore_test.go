@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListRestorePoints(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/VirtualResource/RestorePoints/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/RestorePoints/12345" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{
+				"id": "rp-12345-20240816",
+				"createdOn": "2024-08-16T21:11:10+12:00",
+				"jobName": "DailyAA",
+				"type": "Increment"
+			}]`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	result, err := client.ListRestorePoints(context.Background(), "12345")
+
+	// Then
+	assert.NoError(t, err, "expected no error from ListRestorePoints")
+	assert.Len(t, result, 1)
+	assert.Equal(t, "rp-12345-20240816", result[0].Id, "Restore point ID mismatch")
+	assert.Equal(t, "DailyAA", result[0].JobName, "Job name mismatch")
+	assert.Equal(t, "Increment", result[0].Type, "Restore point type mismatch")
+	assert.Equal(t, time.Date(2024, 8, 16, 9, 11, 10, 0, time.UTC), result[0].CreatedOn.UTC(), "Restore point creation time mismatch")
+}
+
+func TestRestoreVM_TracksTask(t *testing.T) {
+	// Counter to track the number of GetTask calls
+	var getTaskCalls int
+	var payload RestorePayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/Restore
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/Restore" {
+			json.NewDecoder(r.Body).Decode(&payload)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"taskId": "task-7"}`))
+			return
+		}
+
+		// Handle GET /api/Task/{taskId}
+		if r.Method == "GET" && r.URL.Path == "/api/Task/task-7" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the restore finishing on the 3rd call
+			getTaskCalls++
+			response := map[string]interface{}{
+				"id":     "task-7",
+				"status": "Running",
+			}
+			if getTaskCalls >= 3 {
+				response["status"] = "Completed"
+			}
+
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	task, err := client.RestoreVM(context.Background(), "12345", "rp-12345-20240816", RestoreTypeInstantRecovery)
+
+	// Then
+	assert.NoError(t, err, "expected no error from RestoreVM")
+	assert.Equal(t, "task-7", task.Id, "Task ID mismatch")
+	assert.Equal(t, "Completed", task.Status, "Task status mismatch")
+	assert.Equal(t, 3, getTaskCalls, "expected 3 calls to GetTask")
+	assert.Equal(t, RestorePayload{
+		VirtualResourceId: "12345",
+		RestorePointId:    "rp-12345-20240816",
+		RestoreType:       "InstantRecovery",
+	}, payload, "Payload mismatch")
+}
+
+func TestRestoreVM_TaskFailed(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/Restore
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/Restore" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"taskId": "task-8"}`))
+			return
+		}
+
+		// Handle GET /api/Task/{taskId}
+		if r.Method == "GET" && r.URL.Path == "/api/Task/task-8" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": "task-8", "status": "Failed", "message": "restore point is locked by another job"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.RestoreVM(context.Background(), "12345", "rp-12345-20240816", RestoreTypeFull)
+
+	// Then
+	assert.ErrorContains(t, err, "restore point is locked by another job")
+}
+
+func TestRestoreVM_RequiresTask(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/Restore without returning a task
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/Restore" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.RestoreVM(context.Background(), "12345", "rp-12345-20240816", RestoreTypeFull)
+
+	// Then
+	assert.ErrorContains(t, err, "didn't return a task")
+}
+
+func TestWaitForInstantRecoveryDisks(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the disks being mounted after the 2nd call
+			getVMDetailedByIDCalls++
+			disks := []map[string]interface{}{}
+			if getVMDetailedByIDCalls >= 2 {
+				disks = append(disks, map[string]interface{}{"name": "Hard disk 1 (Instant Recovery)", "restorePointId": "rp-12345-20240816"})
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":            12345,
+				"specification": map[string]interface{}{"instantRecoveryDisks": disks},
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	disks, err := client.WaitForInstantRecoveryDisks(context.Background(), "12345", "rp-12345-20240816")
+
+	// Then
+	assert.NoError(t, err, "expected no error from WaitForInstantRecoveryDisks")
+	assert.Len(t, disks, 1)
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+}
+
+func TestWaitForInstantRecoveryDisks_VMMissing(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.WaitForInstantRecoveryDisks(context.Background(), "12345", "rp-12345-20240816")
+
+	// Then
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUnmountInstantRecovery(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/UnmountInstantRecovery
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/UnmountInstantRecovery" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the disks being unmounted after the 2nd call
+			getVMDetailedByIDCalls++
+			disks := []map[string]interface{}{}
+			if getVMDetailedByIDCalls < 2 {
+				disks = append(disks, map[string]interface{}{"name": "Hard disk 1 (Instant Recovery)", "restorePointId": "rp-12345-20240816"})
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":            12345,
+				"specification": map[string]interface{}{"instantRecoveryDisks": disks},
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.UnmountInstantRecovery(context.Background(), "12345", "rp-12345-20240816")
+
+	// Then
+	assert.NoError(t, err, "expected no error from UnmountInstantRecovery")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+func (c *Client) ListSnapshots(ctx context.Context, vmID string) ([]Snapshot, error) {
+	endpoint := fmt.Sprintf("/api/VirtualResource/Snapshots/%s", vmID)
+	resp, err := c.apiRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var snapshots []Snapshot
+	err = json.NewDecoder(resp.Body).Decode(&snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding JSON response: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+func (c *Client) GetSnapshot(ctx context.Context, vmID string, snapshotID string) (*Snapshot, error) {
+	snapshots, err := c.ListSnapshots(ctx, vmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.Id == snapshotID {
+			return &snapshot, nil
+		}
+	}
+
+	return nil, fmt.Errorf("snapshot %s of VM %s: %w", snapshotID, vmID, ErrNotFound)
+}
+
+// CreateSnapshot takes a snapshot of a VM and waits for it to be listed. The
+// API doesn't return the new snapshot's ID, so it is found by comparing the
+// snapshot lists before and after.
+func (c *Client) CreateSnapshot(ctx context.Context, vmID string, snapshot Snapshot) (*Snapshot, error) {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	initialSnapshots, err := c.ListSnapshots(ctx, vmID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing snapshots before creating snapshot: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	for _, s := range initialSnapshots {
+		existing[s.Id] = true
+	}
+
+	tflog.Info(ctx, "creating snapshot", map[string]interface{}{"vm_id": vmID, "name": snapshot.Name})
+	endpoint := "/api/virtualresource/CreateSnapshot"
+	payload := CreateSnapshotPayload{
+		VirtualResourceId: vmID,
+		Name:              snapshot.Name,
+		Description:       snapshot.Description,
+		IncludeMemory:     snapshot.IncludeMemory,
+		Quiesce:           snapshot.Quiesce,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return nil, err
+	}
+
+	var created *Snapshot
+	err = c.waiter().Wait(ctx, fmt.Sprintf("creating snapshot %s of VM %s", snapshot.Name, vmID), func(ctx context.Context) (bool, string, error) {
+		snapshots, err := c.ListSnapshots(ctx, vmID)
+		if err != nil {
+			return false, "", err
+		}
+
+		for _, s := range snapshots {
+			if !existing[s.Id] && s.Name == snapshot.Name {
+				created = &s
+				return true, fmt.Sprintf("snapshot %s created", s.Id), nil
+			}
+		}
+		return false, fmt.Sprintf("%d snapshots", len(snapshots)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tflog.Info(ctx, "snapshot created", map[string]interface{}{"vm_id": vmID, "snapshot_id": created.Id})
+	return created, nil
+}
+
+func (c *Client) DeleteSnapshot(ctx context.Context, vmID string, snapshotID string) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/DeleteSnapshot"
+	payload := SnapshotOperationPayload{
+		VirtualResourceId: vmID,
+		SnapshotId:        snapshotID,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	if err := c.checkOperationResponse(ctx, resp); err != nil {
+		return err
+	}
+
+	return c.waiter().Wait(ctx, fmt.Sprintf("deleting snapshot %s of VM %s", snapshotID, vmID), func(ctx context.Context) (bool, string, error) {
+		_, err := c.GetSnapshot(ctx, vmID, snapshotID)
+		if errors.Is(err, ErrNotFound) {
+			return true, "deleted", nil
+		} else if err != nil {
+			return false, "", err
+		}
+		return false, "present", nil
+	})
+}
+
+// RevertSnapshot returns a VM to the state captured in a snapshot. The
+// snapshot is kept.
+func (c *Client) RevertSnapshot(ctx context.Context, vmID string, snapshotID string) error {
+	unlock, err := c.lockVM(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	endpoint := "/api/virtualresource/RevertSnapshot"
+	payload := SnapshotOperationPayload{
+		VirtualResourceId: vmID,
+		SnapshotId:        snapshotID,
+	}
+
+	resp, err := c.apiRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.invalidateVM(vmID)
+
+	return c.checkOperationResponse(ctx, resp)
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSnapshots(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/VirtualResource/Snapshots/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Snapshots/12345" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{
+				"id": "snapshot-101",
+				"name": "pre-patch",
+				"description": "Before patching",
+				"createdOn": "2024-08-19T14:11:07+12:00",
+				"includeMemory": true,
+				"quiesce": false
+			}]`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	result, err := client.ListSnapshots(context.Background(), "12345")
+
+	// Then
+	assert.NoError(t, err, "expected no error from ListSnapshots")
+	assert.Len(t, result, 1)
+	assert.Equal(t, "snapshot-101", result[0].Id, "Snapshot ID mismatch")
+	assert.Equal(t, "pre-patch", result[0].Name, "Snapshot name mismatch")
+	assert.Equal(t, "Before patching", result[0].Description, "Snapshot description mismatch")
+	assert.True(t, result[0].IncludeMemory, "expected snapshot to include memory")
+	assert.Equal(t, time.Date(2024, 8, 19, 2, 11, 7, 0, time.UTC), result[0].CreatedOn.UTC(), "Snapshot creation time mismatch")
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	// Counter to track the number of ListSnapshots calls
+	var listSnapshotsCalls int
+	var payload CreateSnapshotPayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/CreateSnapshot
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/CreateSnapshot" {
+			json.NewDecoder(r.Body).Decode(&payload)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Snapshots/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Snapshots/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the snapshot appearing on the 3rd call, after one
+			// taken out of band with the same name
+			listSnapshotsCalls++
+			snapshots := []map[string]interface{}{
+				{"id": "snapshot-100", "name": "pre-patch"},
+			}
+			if listSnapshotsCalls >= 3 {
+				snapshots = append(snapshots, map[string]interface{}{"id": "snapshot-101", "name": "pre-patch"})
+			}
+
+			json.NewEncoder(w).Encode(snapshots)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	result, err := client.CreateSnapshot(context.Background(), "12345", Snapshot{
+		Name:          "pre-patch",
+		Description:   "Before patching",
+		IncludeMemory: true,
+	})
+
+	// Then
+	assert.NoError(t, err, "expected no error from CreateSnapshot")
+	assert.Equal(t, "snapshot-101", result.Id, "expected the snapshot that wasn't there before")
+	assert.Equal(t, 3, listSnapshotsCalls, "expected 3 calls to ListSnapshots")
+	assert.Equal(t, CreateSnapshotPayload{
+		VirtualResourceId: "12345",
+		Name:              "pre-patch",
+		Description:       "Before patching",
+		IncludeMemory:     true,
+	}, payload, "Payload mismatch")
+}
+
+func TestCreateSnapshot_RetriesTransientErrors(t *testing.T) {
+	// Given
+	var listSnapshotsCalls int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/virtualresource/CreateSnapshot":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Snapshots/12345":
+			listSnapshotsCalls++
+			switch listSnapshotsCalls {
+			case 1:
+				json.NewEncoder(w).Encode([]map[string]interface{}{})
+			case 2:
+				w.WriteHeader(http.StatusBadGateway)
+			default:
+				json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "snapshot-101", "name": "pre-patch"}})
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	result, err := client.CreateSnapshot(context.Background(), "12345", Snapshot{Name: "pre-patch"})
+
+	// Then
+	assert.NoError(t, err, "expected the 502 to be retried")
+	assert.Equal(t, "snapshot-101", result.Id, "Snapshot ID mismatch")
+}
+
+func TestCreateSnapshot_FailsOnUnauthorized(t *testing.T) {
+	// Given
+	var listSnapshotsCalls int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/virtualresource/CreateSnapshot":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Snapshots/12345":
+			listSnapshotsCalls++
+			if listSnapshotsCalls > 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode([]map[string]interface{}{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.CreateSnapshot(context.Background(), "12345", Snapshot{Name: "pre-patch"})
+
+	// Then
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.Equal(t, 2, listSnapshotsCalls, "expected the wait to stop at the first 401")
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	// Counter to track the number of ListSnapshots calls
+	var listSnapshotsCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/DeleteSnapshot
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/DeleteSnapshot" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Snapshots/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Snapshots/12345" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the snapshot being removed after the 2nd call
+			listSnapshotsCalls++
+			snapshots := []map[string]interface{}{}
+			if listSnapshotsCalls < 2 {
+				snapshots = append(snapshots, map[string]interface{}{"id": "snapshot-101", "name": "pre-patch"})
+			}
+
+			json.NewEncoder(w).Encode(snapshots)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.DeleteSnapshot(context.Background(), "12345", "snapshot-101")
+
+	// Then
+	assert.NoError(t, err, "expected no error from DeleteSnapshot")
+	assert.Equal(t, 2, listSnapshotsCalls, "expected 2 calls to ListSnapshots")
+}
+
+func TestRevertSnapshot_APIFailure(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/RevertSnapshot
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/RevertSnapshot" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"success": false, "message": "snapshot is being consolidated"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.RevertSnapshot(context.Background(), "12345", "snapshot-101")
+
+	// Then
+	assert.ErrorContains(t, err, "snapshot is being consolidated")
+}
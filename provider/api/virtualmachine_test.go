@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -58,6 +60,13 @@ func TestCreateVM(t *testing.T) {
 			return
 		}
 
+		// Handle GET /api/VirtualResource/Detailed/{vmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12346" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 12346, "name": "test-vm-2"})
+			return
+		}
+
 		w.WriteHeader(http.StatusNotFound)
 	}))
 	defer mockServer.Close()
@@ -77,7 +86,7 @@ func TestCreateVM(t *testing.T) {
 	client := testClient(mockServer.URL)
 
 	// When
-	result, err := client.CreateVM(vm)
+	result, err := client.CreateVM(context.Background(), vm)
 
 	// Then
 	assert.NoError(t, err, "expected no error from CreateVM")
@@ -85,6 +94,218 @@ func TestCreateVM(t *testing.T) {
 	assert.Equal(t, 2, getVMByNameCalls, "expected 2 calls to GetVMByName")
 }
 
+func TestCreateVM_WaitsForDetailed(t *testing.T) {
+	var posted bool
+	var detailedCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/Provisioning/VirtualMachine":
+			posted = true
+			w.WriteHeader(http.StatusOK)
+
+		// The VM is listed once provisioning starts
+		case r.Method == "GET" && r.URL.Path == "/api/client/virtualresources/123":
+			response := []map[string]interface{}{}
+			if posted {
+				response = append(response, map[string]interface{}{"id": 12346, "name": "test-vm-2", "hostingLocation": "Auckland"})
+			}
+			json.NewEncoder(w).Encode(response)
+
+		// but the detailed endpoint doesn't know about it until later
+		case r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12346":
+			detailedCalls++
+			if detailedCalls < 3 {
+				http.Error(w, "VM not found", http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 12346, "name": "test-vm-2"})
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	result, err := client.CreateVM(context.Background(), VirtualMachine{ClientId: 123, Name: "test-vm-2"})
+
+	// Then
+	assert.NoError(t, err, "expected no error from CreateVM")
+	assert.Equal(t, "12346", result, "VM ID mismatch")
+	assert.Equal(t, 3, detailedCalls, "expected CreateVM to poll the detailed endpoint until it found the VM")
+}
+
+func TestCreateVM_FailsFastOnDetailedError(t *testing.T) {
+	var detailedCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/Provisioning/VirtualMachine":
+			w.Write([]byte(`{"virtualResourceId": 12346}`))
+
+		case r.Method == "GET" && r.URL.Path == "/api/client/virtualresources/123":
+			w.Write([]byte(`[]`))
+
+		// The detailed lookup is rejected, which retrying won't fix
+		case r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12346":
+			detailedCalls++
+			http.Error(w, "API key revoked", http.StatusUnauthorized)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.CreateVM(context.Background(), VirtualMachine{ClientId: 123, Name: "test-vm-2"})
+
+	// Then
+	assert.ErrorContains(t, err, "API key revoked")
+	assert.Equal(t, 1, detailedCalls, "expected CreateVM to stop after the first rejected lookup")
+}
+
+func TestCreateVM_TracksTask(t *testing.T) {
+	// Counter to track the number of GetTask calls
+	var getTaskCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/client/virtualresources/{clientId}
+		if r.Method == "GET" && r.URL.Path == "/api/client/virtualresources/123" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[]`))
+			return
+		}
+
+		// Handle POST /api/Provisioning/VirtualMachine
+		if r.Method == "POST" && r.URL.Path == "/api/Provisioning/VirtualMachine" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"taskId": "task-1"}`))
+			return
+		}
+
+		// Handle GET /api/Task/{taskId}
+		if r.Method == "GET" && r.URL.Path == "/api/Task/task-1" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			// Simulate the task completing on the 2nd call
+			getTaskCalls++
+			response := map[string]interface{}{
+				"id":     "task-1",
+				"status": "Running",
+			}
+			if getTaskCalls >= 2 {
+				response["status"] = "Completed"
+				response["virtualResourceId"] = 12346
+			}
+
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12346" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": 12346, "name": "test-vm-2"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	result, err := client.CreateVM(context.Background(), VirtualMachine{ClientId: 123, Name: "test-vm-2"})
+
+	// Then
+	assert.NoError(t, err, "expected no error from CreateVM")
+	assert.Equal(t, "12346", result, "VM ID mismatch")
+	assert.Equal(t, 2, getTaskCalls, "expected 2 calls to GetTask")
+}
+
+func TestCreateVM_UsesProvisionedID(t *testing.T) {
+	var listCalls, detailedCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/client/virtualresources/123":
+			listCalls++
+			w.Write([]byte(`[]`))
+
+		// The provisioning response identifies the VM
+		case r.Method == "POST" && r.URL.Path == "/api/Provisioning/VirtualMachine":
+			w.Write([]byte(`{"virtualResourceId": 12346}`))
+
+		case r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/12346":
+			detailedCalls++
+			w.Write([]byte(`{"id": 12346, "name": "test-vm-2"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	result, err := client.CreateVM(context.Background(), VirtualMachine{ClientId: 123, Name: "test-vm-2"})
+
+	// Then
+	assert.NoError(t, err, "expected no error from CreateVM")
+	assert.Equal(t, "12346", result, "VM ID mismatch")
+	assert.Equal(t, 1, listCalls, "expected only the duplicate name check to list VMs")
+	assert.Equal(t, 1, detailedCalls, "expected CreateVM to look the provisioned ID up directly")
+}
+
+func TestCreateVM_RejectsDuplicateName(t *testing.T) {
+	// Given
+	var provisioned bool
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/client/virtualresources/{clientId}
+		if r.Method == "GET" && r.URL.Path == "/api/client/virtualresources/123" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[{"id": 12345, "name": "test-vm-1", "hostingLocation": "Christchurch"}]`))
+			return
+		}
+
+		// Handle POST /api/Provisioning/VirtualMachine
+		if r.Method == "POST" && r.URL.Path == "/api/Provisioning/VirtualMachine" {
+			provisioned = true
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.CreateVM(context.Background(), VirtualMachine{ClientId: 123, Name: "test-vm-1"})
+
+	// Then
+	assert.ErrorContains(t, err, "already exists")
+	assert.False(t, provisioned, "expected no provisioning request")
+}
+
 func TestGetVMByName(t *testing.T) {
 	// Given
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -113,7 +334,7 @@ func TestGetVMByName(t *testing.T) {
 	client := testClient(mockServer.URL)
 
 	// When
-	result, err := client.GetVMByName("test-vm-1", 123)
+	result, err := client.GetVMByName(context.Background(), "test-vm-1", 123)
 
 	// Then
 	assert.NoError(t, err, "expected no error from GetVMByName")
@@ -163,7 +384,7 @@ func TestGetVMDetailedByID(t *testing.T) {
 	client := testClient(mockServer.URL)
 
 	// When
-	result, err := client.GetVMDetailedByID("12345")
+	result, err := client.GetVMDetailedByID(context.Background(), "12345")
 
 	// Then
 	assert.NoError(t, err, "expected no error from GetVMByName")
@@ -172,6 +393,7 @@ func TestGetVMDetailedByID(t *testing.T) {
 	assert.Equal(t, "DISKVM0000", result.Name, "VM Name mismatch")
 	assert.Equal(t, 1, result.Specification.Cores, "Cores mismatch")
 	assert.Equal(t, 4, result.Specification.MemoryGb, "Memory size mismatch")
+	assert.Equal(t, 4096, result.Specification.MemorySizeMb(), "Memory size in MB mismatch")
 	assert.Equal(t, "vm-000", result.Specification.MoRef, "MoRef mismatch")
 
 	disk := result.Specification.VirtualDisks[0]
@@ -184,6 +406,57 @@ func TestGetVMDetailedByID(t *testing.T) {
 	assert.Equal(t, "Christchurch", result.HostingLocation.Name, "Hosting Location Name mismatch")
 }
 
+// Test against a response recorded from the production API, which returns
+// float capacities, backend tier names and nulls the hand-written maps omit.
+func TestGetVMDetailedByID_Recorded(t *testing.T) {
+	// Given
+	mockServer := replayServer(t, "detailed_vm.json")
+	client := testClient(mockServer.URL)
+
+	// When
+	result, err := client.GetVMDetailedByID(context.Background(), "1001")
+
+	// Then
+	assert.NoError(t, err, "expected no error from GetVMDetailedByID")
+	assert.Equal(t, json.Number("1001"), result.Id, "VM ID mismatch")
+	assert.Equal(t, "DISKVM0000", result.Name, "VM Name mismatch")
+	assert.Equal(t, "vm-1002", result.Specification.MoRef, "MoRef mismatch")
+	assert.Equal(t, "On", result.Specification.PowerState, "Power state mismatch")
+	assert.Equal(t, 4, result.Specification.TotalCores(), "Total cores mismatch")
+	assert.Nil(t, result.MountedISO, "expected no mounted ISO")
+
+	assert.Len(t, result.Specification.VirtualDisks, 1)
+	disk := result.Specification.VirtualDisks[0]
+	assert.Equal(t, 100, disk.Capacity, "Disk capacity mismatch")
+	assert.Equal(t, "vStorageT1", disk.Tier, "Disk storage profile mismatch")
+	assert.Equal(t, "Hard disk 1", disk.Name, "Disk name mismatch")
+
+	assert.Len(t, result.Specification.NetworkDevices, 1)
+	nic := result.Specification.NetworkDevices[0]
+	assert.Equal(t, "WAN", nic.NetworkName, "Network name mismatch")
+	assert.True(t, nic.Connected, "expected NIC to be connected")
+
+	assert.Len(t, result.Licenses, 3)
+	assert.Equal(t, "Windows Server 2016", result.Licenses[0].Name, "License name mismatch")
+	assert.Nil(t, result.AssignedLicense(), "expected no license to be assigned")
+
+	assert.Equal(t, "Microsoft Windows Server 2019 (64-bit)", result.GuestOS, "Guest OS mismatch")
+
+	assert.Equal(t, "Christchurch", result.HostingLocation.Name, "Hosting Location Name mismatch")
+}
+
+func TestGetVMDetailedByID_RecordedNotFound(t *testing.T) {
+	// Given
+	mockServer := replayServer(t, "detailed_vm.json")
+	client := testClient(mockServer.URL)
+
+	// When
+	_, err := client.GetVMDetailedByID(context.Background(), "1003")
+
+	// Then
+	assert.ErrorContains(t, err, "404 Not Found")
+}
+
 func TestPowerOffVM(t *testing.T) {
 	// Given
 	expectedPayload := PowerOperationPayload{
@@ -216,12 +489,270 @@ func TestPowerOffVM(t *testing.T) {
 	client := testClient(mockServer.URL)
 
 	// When
-	err := client.PowerOffVM("7452")
+	err := client.PowerOffVM(context.Background(), "7452")
 
 	// Then
 	assert.NoError(t, err, "expected no error from PowerOffVM")
 }
 
+func TestPowerOffVM_ReportsFailure(t *testing.T) {
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/poweroperation
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/poweroperation" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"success": false, "message": "VMware Tools not running"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.PowerOffVM(context.Background(), "7452")
+
+	// Then
+	assert.ErrorContains(t, err, "VMware Tools not running")
+}
+
+func TestWaitForPowerState(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/7452" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the VM shutting down by the 3rd call
+			getVMDetailedByIDCalls++
+			powerState := PowerStateOn
+			if getVMDetailedByIDCalls >= 3 {
+				powerState = PowerStateOff
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":            7452,
+				"specification": map[string]interface{}{"powerState": powerState},
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.WaitForPowerState(context.Background(), "7452", PowerStateOff)
+
+	// Then
+	assert.NoError(t, err, "expected no error from WaitForPowerState")
+	assert.Equal(t, 3, getVMDetailedByIDCalls, "expected 3 calls to GetVMDetailedByID")
+}
+
+func TestUpdateCPU(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+	var receivedPayload UpdateCPUPayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/UpdateCPU
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/UpdateCPU" {
+			json.NewDecoder(r.Body).Decode(&receivedPayload)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/7452" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the new topology showing up on the 2nd call
+			getVMDetailedByIDCalls++
+			specification := map[string]interface{}{"cores": 2, "sockets": 1}
+			if getVMDetailedByIDCalls >= 2 {
+				specification = map[string]interface{}{"cores": 1, "sockets": 4}
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 7452, "specification": specification})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.UpdateCPU(context.Background(), "7452", 4, 1)
+
+	// Then
+	assert.NoError(t, err, "expected no error from UpdateCPU")
+	assert.Equal(t, UpdateCPUPayload{VirtualResourceId: "7452", Cores: 4, CoresPerSocket: 1}, receivedPayload, "Payload mismatch")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+}
+
+func TestUpdateMemory(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+	var receivedPayload UpdateMemoryPayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle POST /api/virtualresource/UpdateMemory
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/UpdateMemory" {
+			json.NewDecoder(r.Body).Decode(&receivedPayload)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/7452" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the new size showing up on the 2nd call
+			getVMDetailedByIDCalls++
+			specification := map[string]interface{}{"memoryGb": 4, "memoryMb": 4096}
+			if getVMDetailedByIDCalls >= 2 {
+				specification = map[string]interface{}{"memoryGb": 4, "memoryMb": 4608}
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 7452, "specification": specification})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.UpdateMemory(context.Background(), "7452", 4608)
+
+	// Then
+	assert.NoError(t, err, "expected no error from UpdateMemory")
+	assert.Equal(t, UpdateMemoryPayload{VirtualResourceId: "7452", MemoryMb: 4608}, receivedPayload, "Payload mismatch")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+}
+
+func TestSpecificationHasMemoryMb(t *testing.T) {
+	tests := []struct {
+		name          string
+		specification Specification
+		memoryMb      int
+		want          bool
+	}{
+		{"MB reported, equal", Specification{MemoryGb: 4, MemoryMb: 4608}, 4608, true},
+		{"MB reported, different", Specification{MemoryGb: 4, MemoryMb: 4096}, 4608, false},
+		{"whole GB only, exact", Specification{MemoryGb: 4}, 4096, true},
+		{"whole GB only, rounded down", Specification{MemoryGb: 4}, 4608, true},
+		{"whole GB only, rounded up", Specification{MemoryGb: 5}, 4608, true},
+		{"whole GB only, different", Specification{MemoryGb: 6}, 4608, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			got := tt.specification.HasMemoryMb(tt.memoryMb)
+
+			// Then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenameVM(t *testing.T) {
+	// Counter to track the number of GetVMDetailedByID calls
+	var getVMDetailedByIDCalls int
+	var receivedPayload RenameVMPayload
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/client/virtualresources/{clientId}
+		if r.Method == "GET" && r.URL.Path == "/api/client/virtualresources/123" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id": 7452, "name": "test-vm-1", "hostingLocation": "Christchurch"}]`))
+			return
+		}
+
+		// Handle POST /api/virtualresource/Rename
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/Rename" {
+			json.NewDecoder(r.Body).Decode(&receivedPayload)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle GET /api/VirtualResource/Detailed/{VmId}
+		if r.Method == "GET" && r.URL.Path == "/api/VirtualResource/Detailed/7452" {
+			w.Header().Set("Content-Type", "application/json")
+
+			// Simulate the new name showing up on the 2nd call
+			getVMDetailedByIDCalls++
+			name := "test-vm-1"
+			if getVMDetailedByIDCalls >= 2 {
+				name = "test-vm-2"
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 7452, "name": name})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.RenameVM(context.Background(), "7452", 123, "test-vm-2")
+
+	// Then
+	assert.NoError(t, err, "expected no error from RenameVM")
+	assert.Equal(t, RenameVMPayload{VirtualResourceId: "7452", Name: "test-vm-2"}, receivedPayload, "Payload mismatch")
+	assert.Equal(t, 2, getVMDetailedByIDCalls, "expected 2 calls to GetVMDetailedByID")
+}
+
+func TestRenameVM_RejectsDuplicateName(t *testing.T) {
+	// Given
+	var renamed bool
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/client/virtualresources/{clientId}
+		if r.Method == "GET" && r.URL.Path == "/api/client/virtualresources/123" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id": 7452, "name": "test-vm-1"}, {"id": 7453, "name": "test-vm-2"}]`))
+			return
+		}
+
+		// Handle POST /api/virtualresource/Rename
+		if r.Method == "POST" && r.URL.Path == "/api/virtualresource/Rename" {
+			renamed = true
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+
+	// When
+	err := client.RenameVM(context.Background(), "7452", 123, "test-vm-2")
+
+	// Then
+	assert.ErrorContains(t, err, "already exists")
+	assert.False(t, renamed, "expected no rename request")
+}
+
 func TestDeleteVM(t *testing.T) {
 	// Given
 	expectedPayload := DeleteVMOperationPayload{
@@ -254,8 +785,54 @@ func TestDeleteVM(t *testing.T) {
 	client := testClient(mockServer.URL)
 
 	// When
-	err := client.DeleteVM("7452", "vm-000")
+	err := client.DeleteVM(context.Background(), "7452", "vm-000")
 
 	// Then
 	assert.NoError(t, err, "expected no error from DeleteVM")
 }
+
+func TestListVirtualResources(t *testing.T) {
+	// Counter to track the number of list downloads
+	var listCalls int
+
+	// Given
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /api/client/virtualresources/{clientId}
+		if r.Method == "GET" && r.URL.Path == "/api/client/virtualresources/123" {
+			listCalls++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[
+				{"id": 12345, "clientId": 123, "name": "test-vm-1", "hostingLocation": "Christchurch"},
+				{"id": 12346, "clientId": 123, "name": "test-vm-2", "hostingLocation": "Auckland"}
+			]`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := testClient(mockServer.URL)
+	client.CacheTTL = time.Minute
+
+	// When
+	result, err := client.ListVirtualResources(context.Background(), 123)
+	vmID, nameErr := client.GetVMByName(context.Background(), "test-vm-2", 123)
+	exists, existsErr := client.VMExists(context.Background(), "99999", 123)
+
+	// Then
+	assert.NoError(t, err, "expected no error from ListVirtualResources")
+	assert.Equal(t, []VirtualResourceSummary{
+		{Id: 12345, ClientId: 123, Name: "test-vm-1", HostingLocation: "Christchurch"},
+		{Id: 12346, ClientId: 123, Name: "test-vm-2", HostingLocation: "Auckland"},
+	}, result)
+
+	assert.NoError(t, nameErr, "expected no error from GetVMByName")
+	assert.Equal(t, "12346", vmID, "VM ID mismatch")
+
+	assert.NoError(t, existsErr, "expected no error from VMExists")
+	assert.False(t, exists, "expected unknown VM not to exist")
+
+	assert.Equal(t, 1, listCalls, "expected lookups to share one list download")
+}
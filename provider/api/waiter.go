@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// DefaultPollInterval is how often asynchronous operations are re-checked.
+	DefaultPollInterval = 5 * time.Second
+	// DefaultMaxPollInterval caps the interval once backoff has been applied.
+	DefaultMaxPollInterval = 30 * time.Second
+	// DefaultPollBackoff is the factor the interval grows by after each check.
+	DefaultPollBackoff = 1.5
+	// DefaultWaitTimeout bounds a single wait. It matches the longest
+	// default resource timeout, a restore's.
+	DefaultWaitTimeout = 4 * time.Hour
+	// DefaultVisibilityTimeout bounds the wait for a provisioned VM to show
+	// up in the detailed endpoint.
+	DefaultVisibilityTimeout = 10 * time.Minute
+)
+
+// WaitFunc checks the state of an asynchronous operation. It reports done
+// once the operation has finished, along with a short description of the
+// state it observed. Returning an error stops the wait immediately, unless
+// the error is transient, such as the API throttling or failing, in which
+// case the check is retried until the wait times out.
+type WaitFunc func(ctx context.Context) (done bool, state string, err error)
+
+// Waiter polls a WaitFunc until it reports completion, the timeout passes or
+// the context is cancelled.
+type Waiter struct {
+	// Interval is the delay before the second check.
+	Interval time.Duration
+	// MaxInterval caps the delay between checks. Zero disables the cap.
+	MaxInterval time.Duration
+	// Backoff multiplies the delay after every check. Values <= 1 keep the
+	// interval constant.
+	Backoff float64
+	// Timeout bounds the whole wait. Zero relies on the context deadline.
+	Timeout time.Duration
+}
+
+// WaitError is returned when a wait does not complete successfully. It keeps
+// the last state observed so callers can report what the API was doing.
+type WaitError struct {
+	Operation string
+	LastState string
+	Attempts  int
+	Elapsed   time.Duration
+	Err       error
+}
+
+func (e *WaitError) Error() string {
+	state := e.LastState
+	if state == "" {
+		state = "unknown"
+	}
+	return fmt.Sprintf("%s did not complete after %d checks over %s (last state: %s): %v",
+		e.Operation, e.Attempts, e.Elapsed.Round(time.Second), state, e.Err)
+}
+
+func (e *WaitError) Unwrap() error {
+	return e.Err
+}
+
+// Wait runs check until it reports done. operation is used in log messages
+// and errors, e.g. "adding disk to VM 123".
+func (w Waiter) Wait(ctx context.Context, operation string, check WaitFunc) error {
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	start := time.Now()
+	var lastState string
+	for attempt := 1; ; attempt++ {
+		done, state, err := check(ctx)
+		if state != "" {
+			lastState = state
+		}
+
+		tflog.Debug(ctx, "polled asynchronous operation", map[string]interface{}{
+			"operation": operation,
+			"attempt":   attempt,
+			"state":     lastState,
+			"done":      done,
+		})
+
+		if err != nil && !isTransient(err) {
+			return &WaitError{Operation: operation, LastState: lastState, Attempts: attempt, Elapsed: time.Since(start), Err: err}
+		}
+		if err != nil {
+			lastState = fmt.Sprintf("retrying after: %v", err)
+			tflog.Debug(ctx, "retrying transient error", map[string]interface{}{"operation": operation, "error": err.Error()})
+		} else if done {
+			return nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err := ctx.Err()
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out: %w", err)
+			}
+			return &WaitError{Operation: operation, LastState: lastState, Attempts: attempt, Elapsed: time.Since(start), Err: err}
+		case <-timer.C:
+		}
+
+		if w.Backoff > 1 {
+			interval = time.Duration(float64(interval) * w.Backoff)
+			if w.MaxInterval > 0 && interval > w.MaxInterval {
+				interval = w.MaxInterval
+			}
+		}
+	}
+}
+
+// waiter returns a Waiter configured from the client's polling settings.
+func (c *Client) waiter() Waiter {
+	return Waiter{
+		Interval:    c.PollInterval,
+		MaxInterval: DefaultMaxPollInterval,
+		Backoff:     DefaultPollBackoff,
+		Timeout:     c.WaitTimeout,
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaiterWait(t *testing.T) {
+	// Given
+	var calls int
+	waiter := Waiter{Interval: time.Millisecond, Backoff: 2, MaxInterval: 4 * time.Millisecond}
+
+	// When
+	err := waiter.Wait(context.Background(), "test operation", func(ctx context.Context) (bool, string, error) {
+		calls++
+		return calls == 3, "running", nil
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls, "expected 3 checks")
+}
+
+func TestWaiterWait_Timeout(t *testing.T) {
+	// Given
+	waiter := Waiter{Interval: time.Millisecond, Timeout: 20 * time.Millisecond}
+
+	// When
+	err := waiter.Wait(context.Background(), "test operation", func(ctx context.Context) (bool, string, error) {
+		return false, "still running", nil
+	})
+
+	// Then
+	var waitErr *WaitError
+	assert.ErrorAs(t, err, &waitErr)
+	assert.Equal(t, "still running", waitErr.LastState, "last state mismatch")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaiterWait_CheckError(t *testing.T) {
+	// Given
+	var calls int
+	checkErr := errors.New("task failed")
+	waiter := Waiter{Interval: time.Millisecond}
+
+	// When
+	err := waiter.Wait(context.Background(), "test operation", func(ctx context.Context) (bool, string, error) {
+		calls++
+		return false, "failed", checkErr
+	})
+
+	// Then
+	assert.ErrorIs(t, err, checkErr)
+	assert.Equal(t, 1, calls, "expected the wait to stop after the first error")
+}
+
+func TestWaiterWait_RetriesTransientErrors(t *testing.T) {
+	// Given
+	var calls int
+	waiter := Waiter{Interval: time.Millisecond}
+
+	// When
+	err := waiter.Wait(context.Background(), "test operation", func(ctx context.Context) (bool, string, error) {
+		calls++
+		if calls < 3 {
+			return false, "", &APIError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return true, "done", nil
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls, "expected transient errors to be retried")
+}
+
+func TestWaiterWait_TransientErrorTimeout(t *testing.T) {
+	// Given
+	waiter := Waiter{Interval: time.Millisecond, Timeout: 20 * time.Millisecond}
+
+	// When
+	err := waiter.Wait(context.Background(), "test operation", func(ctx context.Context) (bool, string, error) {
+		return false, "", &APIError{StatusCode: http.StatusTooManyRequests}
+	})
+
+	// Then
+	var waitErr *WaitError
+	assert.ErrorAs(t, err, &waitErr)
+	assert.Contains(t, waitErr.LastState, "retrying after", "last state mismatch")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaiterWait_Cancelled(t *testing.T) {
+	// Given
+	ctx, cancel := context.WithCancel(context.Background())
+	waiter := Waiter{Interval: time.Millisecond}
+
+	// When
+	err := waiter.Wait(ctx, "test operation", func(ctx context.Context) (bool, string, error) {
+		cancel()
+		return false, "running", nil
+	})
+
+	// Then
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,60 @@
+package licenses_test
+
+import (
+	"fmt"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// Test configuration
+func testAccLicensesConfig(apiURL string) string {
+	return acctest.ProviderConfig(apiURL) + fmt.Sprintf(`
+resource "vbridge_virtual_machine" "vm" {
+  client_id                             = %d
+  name                                  = "test-vm-licenses"
+  template                              = "Windows2022_Standard_30GB"
+  guest_os_id                           = "windows2019srv_64Guest"
+  cores                                 = 2
+  memory_size                           = 6
+  operating_system_disk_storage_profile = "vStorageT1"
+  hosting_location_id                   = "vcchcres"
+  hosting_location_name                 = "Christchurch"
+  hosting_location_default_network      = "CHC-CUST-SDC-WAN"
+  backup_type                           = "vBackupDisk"
+  license                               = "Windows Server 2022"
+}
+
+data "vbridge_licenses" "vm" {
+  vm_id = vbridge_virtual_machine.vm.vm_id
+}
+`, acctest.ClientId)
+}
+
+// Test for listing the licenses offered for a Windows VM
+func TestAccLicensesDataSource_basic(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccLicensesConfig(mockAPI.URL),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vbridge_licenses.vm", "licenses.#", "3"),
+					resource.TestCheckResourceAttr("data.vbridge_licenses.vm", "licenses.0.name", "Windows Server 2016"),
+					resource.TestCheckResourceAttr("data.vbridge_licenses.vm", "licenses.0.assigned", "false"),
+					resource.TestCheckResourceAttr("data.vbridge_licenses.vm", "licenses.2.name", "Windows Server 2022"),
+					resource.TestCheckResourceAttr("data.vbridge_licenses.vm", "licenses.2.assigned", "true"),
+					resource.TestCheckResourceAttrPair("data.vbridge_licenses.vm", "licenses.2.license_id", "vbridge_virtual_machine.vm", "license_id"),
+					resource.TestCheckResourceAttrPair("data.vbridge_licenses.vm", "licenses.2.license_key", "vbridge_virtual_machine.vm", "license_key"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package licenses
+
+import (
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type dataSourceModel struct {
+	VmId     types.String `tfsdk:"vm_id"`
+	Licenses types.List   `tfsdk:"licenses"`
+}
+
+var licenseAttrTypes = map[string]attr.Type{
+	"license_id":  types.Int64Type,
+	"name":        types.StringType,
+	"license_key": types.StringType,
+	"assigned":    types.BoolType,
+}
+
+func (m *dataSourceModel) setLicenses(licenses []api.License) {
+	values := make([]attr.Value, 0, len(licenses))
+	for _, license := range licenses {
+		values = append(values, types.ObjectValueMust(licenseAttrTypes, map[string]attr.Value{
+			"license_id":  types.Int64Value(int64(license.LicenseId)),
+			"name":        types.StringValue(license.Name),
+			"license_key": types.StringValue(license.LicenseKey),
+			"assigned":    types.BoolValue(license.Assigned()),
+		}))
+	}
+	m.Licenses = types.ListValueMust(types.ObjectType{AttrTypes: licenseAttrTypes}, values)
+}
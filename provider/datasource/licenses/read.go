@@ -0,0 +1,24 @@
+package licenses
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+)
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config dataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	licenses, err := d.client.ListLicenses(ctx, config.VmId.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading licenses", err.Error())
+		return
+	}
+
+	config.setLicenses(licenses)
+	resp.Diagnostics.Append(resp.State.Set(ctx, config)...)
+}
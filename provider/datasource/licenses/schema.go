@@ -0,0 +1,40 @@
+package licenses
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+)
+
+func (d *DataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the guest OS licenses offered for a virtual machine and which one it consumes.",
+		Attributes: map[string]schema.Attribute{
+			"vm_id": schema.StringAttribute{
+				Required: true,
+			},
+			"licenses": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"license_id": schema.Int64Attribute{
+							Computed:    true,
+							Description: "0 unless the license is assigned.",
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"license_key": schema.StringAttribute{
+							Computed:  true,
+							Sensitive: true,
+						},
+						"assigned": schema.BoolAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
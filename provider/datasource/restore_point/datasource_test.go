@@ -0,0 +1,77 @@
+package restorepoint_test
+
+import (
+	"fmt"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// Test configuration
+func testAccRestorePointConfig(apiURL string, backupType string) string {
+	return acctest.ProviderConfig(apiURL) + fmt.Sprintf(`
+resource "vbridge_virtual_machine" "vm" {
+  client_id                             = %d
+  name                                  = "test-vm-restore-point"
+  template                              = "Windows2022_Standard_30GB"
+  guest_os_id                           = "windows2019srv_64Guest"
+  cores                                 = 2
+  memory_size                           = 6
+  operating_system_disk_storage_profile = "vStorageT1"
+  hosting_location_id                   = "vcchcres"
+  hosting_location_name                 = "Christchurch"
+  hosting_location_default_network      = "CHC-CUST-SDC-WAN"
+  backup_type                           = %q
+}
+
+data "vbridge_restore_point" "vm" {
+  vm_id = vbridge_virtual_machine.vm.vm_id
+}
+`, acctest.ClientId, backupType)
+}
+
+// Test for listing the restore points of a backed up VM
+func TestAccRestorePointDataSource_basic(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccRestorePointConfig(mockAPI.URL, "vBackupDisk"),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vbridge_restore_point.vm", "restore_points.#", "7"),
+					resource.TestCheckResourceAttr("data.vbridge_restore_point.vm", "restore_points.0.type", "Full"),
+					resource.TestCheckResourceAttrPair("data.vbridge_restore_point.vm", "latest_id", "data.vbridge_restore_point.vm", "restore_points.6.id"),
+				),
+			},
+		},
+	})
+}
+
+// Test for a VM that isn't backed up
+func TestAccRestorePointDataSource_noBackups(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccRestorePointConfig(mockAPI.URL, "vBackupNone"),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vbridge_restore_point.vm", "restore_points.#", "0"),
+					resource.TestCheckNoResourceAttr("data.vbridge_restore_point.vm", "latest_id"),
+				),
+			},
+		},
+	})
+}
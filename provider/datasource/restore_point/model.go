@@ -0,0 +1,45 @@
+package restorepoint
+
+import (
+	"sort"
+	"terraform-provider-vbridge/api"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type dataSourceModel struct {
+	VmId          types.String `tfsdk:"vm_id"`
+	LatestId      types.String `tfsdk:"latest_id"`
+	RestorePoints types.List   `tfsdk:"restore_points"`
+}
+
+var restorePointAttrTypes = map[string]attr.Type{
+	"id":         types.StringType,
+	"created_on": types.StringType,
+	"job_name":   types.StringType,
+	"type":       types.StringType,
+}
+
+func (m *dataSourceModel) setRestorePoints(restorePoints []api.RestorePoint) {
+	sort.SliceStable(restorePoints, func(i, j int) bool {
+		return restorePoints[i].CreatedOn.Before(restorePoints[j].CreatedOn)
+	})
+
+	values := make([]attr.Value, 0, len(restorePoints))
+	for _, point := range restorePoints {
+		values = append(values, types.ObjectValueMust(restorePointAttrTypes, map[string]attr.Value{
+			"id":         types.StringValue(point.Id),
+			"created_on": types.StringValue(point.CreatedOn.Format(time.RFC3339)),
+			"job_name":   types.StringValue(point.JobName),
+			"type":       types.StringValue(point.Type),
+		}))
+	}
+	m.RestorePoints = types.ListValueMust(types.ObjectType{AttrTypes: restorePointAttrTypes}, values)
+
+	m.LatestId = types.StringNull()
+	if len(restorePoints) > 0 {
+		m.LatestId = types.StringValue(restorePoints[len(restorePoints)-1].Id)
+	}
+}
@@ -0,0 +1,24 @@
+package restorepoint
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+)
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config dataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	restorePoints, err := d.client.ListRestorePoints(ctx, config.VmId.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading restore points", err.Error())
+		return
+	}
+
+	config.setRestorePoints(restorePoints)
+	resp.Diagnostics.Append(resp.State.Set(ctx, config)...)
+}
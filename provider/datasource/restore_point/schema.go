@@ -0,0 +1,44 @@
+package restorepoint
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+)
+
+func (d *DataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the backups a virtual machine can be restored from.",
+		Attributes: map[string]schema.Attribute{
+			"vm_id": schema.StringAttribute{
+				Required: true,
+			},
+			"latest_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "ID of the most recent restore point, or null if the VM has none.",
+			},
+			"restore_points": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Restore points, oldest first.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed: true,
+						},
+						"created_on": schema.StringAttribute{
+							Computed: true,
+						},
+						"job_name": schema.StringAttribute{
+							Computed: true,
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Full or Increment.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,74 @@
+// Package acctest runs acceptance tests against the bundled mock-api, so they
+// need no network access or vBridge credentials.
+package acctest
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"terraform-provider-vbridge/api"
+	"terraform-provider-vbridge/provider"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+
+	"localhost-api/mockapi"
+)
+
+// Credentials accepted by the mock API.
+const (
+	APIKey    = "yourapikeygoeshere"
+	UserEmail = "you-users@yourcompany.com"
+	ClientId  = 599
+)
+
+// ProtoV6ProviderFactories serves the provider in-process for resource.Test.
+var ProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"vbridge": providerserver.NewProtocol6WithError(provider.New("test")()),
+}
+
+// NewMockAPI starts a mock API with its own in-memory state, so tests using
+// it can run in parallel. The server is closed when the test finishes.
+func NewMockAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return NewMockAPIWithOptions(t, mockapi.Options{})
+}
+
+// NewMockAPIWithOptions is NewMockAPI with options such as faults to inject.
+func NewMockAPIWithOptions(t *testing.T, opts mockapi.Options) *httptest.Server {
+	t.Helper()
+
+	mock, err := mockapi.New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(mock.Handler())
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Client returns an uncached API client for checking the mock's state
+// directly from CheckDestroy and Check functions.
+func Client(apiURL string) (*api.Client, error) {
+	client, err := api.NewClient(apiURL, APIKey, UserEmail)
+	if err != nil {
+		return nil, err
+	}
+	client.CacheTTL = 0
+
+	return client, nil
+}
+
+// ProviderConfig returns a provider block pointing at apiURL.
+func ProviderConfig(apiURL string) string {
+	return fmt.Sprintf(`
+provider "vbridge" {
+  api_url    = %q
+  api_key    = %q
+  user_email = %q
+}
+`, apiURL, APIKey, UserEmail)
+}
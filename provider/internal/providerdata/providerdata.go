@@ -0,0 +1,12 @@
+// Package providerdata holds what the provider passes to its resources and
+// data sources once it is configured.
+package providerdata
+
+import "terraform-provider-vbridge/api"
+
+// Data is the provider's ResourceData and DataSourceData.
+type Data struct {
+	Client *api.Client
+	// DefaultTags are merged into the tags of every VM.
+	DefaultTags map[string]string
+}
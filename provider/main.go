@@ -1,13 +1,27 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"log"
 	"terraform-provider-vbridge/provider"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
 
+// version is set by the release build.
+var version = "dev"
+
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: provider.Provider,
+	var debug bool
+	flag.BoolVar(&debug, "debug", false, "run the provider with support for debuggers like delve")
+	flag.Parse()
+
+	err := providerserver.Serve(context.Background(), provider.New(version), providerserver.ServeOpts{
+		Address: "durankeeley.com/vbridge/vbridge-vm",
+		Debug:   debug,
 	})
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -2,55 +2,165 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"terraform-provider-vbridge/api"
+	"terraform-provider-vbridge/datasource/licenses"
+	"terraform-provider-vbridge/datasource/restore_point"
+	"terraform-provider-vbridge/internal/providerdata"
 	"terraform-provider-vbridge/resource/virtualmachine"
 	"terraform-provider-vbridge/resource/virtualmachine_additionaldisk"
+	"terraform-provider-vbridge/resource/virtualmachine_restore"
+	"terraform-provider-vbridge/resource/virtualmachine_snapshot"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func Provider() *schema.Provider {
-	return &schema.Provider{
-		Schema: map[string]*schema.Schema{
-			"api_url": {
-				Type:     schema.TypeString,
+const (
+	defaultRequestsPerSecond     = 5
+	defaultMaxConcurrentRequests = 4
+)
+
+type vbridgeProvider struct {
+	version string
+}
+
+type providerModel struct {
+	APIUrl                types.String  `tfsdk:"api_url"`
+	APIKey                types.String  `tfsdk:"api_key"`
+	UserEmail             types.String  `tfsdk:"user_email"`
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	DefaultTags           types.Map     `tfsdk:"default_tags"`
+}
+
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &vbridgeProvider{version: version}
+	}
+}
+
+func (p *vbridgeProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "vbridge"
+	resp.Version = p.version
+}
+
+func (p *vbridgeProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"api_url": schema.StringAttribute{
 				Required: true,
 			},
-			"api_key": {
-				Type:      schema.TypeString,
-				Required:  true,
+			"api_key": schema.StringAttribute{
+				Optional:  true,
 				Sensitive: true,
+				Description: "vBridge API key. Falls back to the VBRIDGE_API_KEY environment variable. " +
+					"Provider configuration is never written to state, so an ephemeral value can be used.",
 			},
-			"user_email": {
-				Type:     schema.TypeString,
+			"user_email": schema.StringAttribute{
 				Required: true,
 			},
+			"requests_per_second": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Maximum number of API requests per second made by the provider. Defaults to 5, set to 0 to disable.",
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Maximum number of API requests in flight at once. Defaults to 4, set to 0 to disable.",
+			},
+			"default_tags": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Tags added to every virtual machine. Tags set on a VM take precedence.",
+			},
 		},
-		ResourcesMap: map[string]*schema.Resource{
-			"vbridge_virtual_machine":                virtualmachine.Resource(),
-			"vbridge_virtual_machine_additionaldisk": additionaldisk.Resource(),
-		},
-		ConfigureContextFunc: configureProvider,
 	}
 }
 
-func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	var diags diag.Diagnostics
+func (p *vbridgeProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config providerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Unknown values are only possible during validation; the provider is
+	// configured again once they are known.
+	if config.APIUrl.IsUnknown() || config.APIKey.IsUnknown() || config.UserEmail.IsUnknown() {
+		return
+	}
+
+	apiKey := config.APIKey.ValueString()
+	if config.APIKey.IsNull() {
+		apiKey = os.Getenv("VBRIDGE_API_KEY")
+	}
+	if apiKey == "" {
+		resp.Diagnostics.AddAttributeError(path.Root("api_key"), "Missing API key",
+			"Set `api_key` in the provider configuration or the VBRIDGE_API_KEY environment variable.")
+	}
+
+	requestsPerSecond := float64(defaultRequestsPerSecond)
+	if !config.RequestsPerSecond.IsNull() {
+		requestsPerSecond = config.RequestsPerSecond.ValueFloat64()
+	}
+	if requestsPerSecond < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("requests_per_second"), "Invalid requests_per_second",
+			fmt.Sprintf("`requests_per_second` must be at least 0, got: %g", requestsPerSecond))
+	}
 
-	apiURL := d.Get("api_url").(string)
-	apiKey := d.Get("api_key").(string)
-	userEmail := d.Get("user_email").(string)
+	maxConcurrentRequests := int64(defaultMaxConcurrentRequests)
+	if !config.MaxConcurrentRequests.IsNull() {
+		maxConcurrentRequests = config.MaxConcurrentRequests.ValueInt64()
+	}
+	if maxConcurrentRequests < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("max_concurrent_requests"), "Invalid max_concurrent_requests",
+			fmt.Sprintf("`max_concurrent_requests` must be at least 0, got: %d", maxConcurrentRequests))
+	}
+
+	defaultTags := make(map[string]string)
+	if !config.DefaultTags.IsNull() && !config.DefaultTags.IsUnknown() {
+		resp.Diagnostics.Append(config.DefaultTags.ElementsAs(ctx, &defaultTags, false)...)
+	}
+	for key, value := range defaultTags {
+		if err := api.ValidateTag(key, value); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("default_tags"), "Invalid default_tags", err.Error())
+		}
+	}
 
-	client, err := api.NewClient(apiURL, apiKey, userEmail)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, err := api.NewClient(config.APIUrl.ValueString(), apiKey, config.UserEmail.ValueString())
 	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to create API client",
-			Detail:   err.Error(),
-		})
-		return nil, diags
+		resp.Diagnostics.AddError("Unable to create API client", err.Error())
+		return
 	}
 
-	return client, diags
+	client.SetRateLimit(requestsPerSecond, int(maxConcurrentRequests))
+
+	data := &providerdata.Data{Client: client, DefaultTags: defaultTags}
+	resp.ResourceData = data
+	resp.DataSourceData = data
+}
+
+func (p *vbridgeProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		virtualmachine.NewResource,
+		additionaldisk.NewResource,
+		snapshot.NewResource,
+		restore.NewResource,
+	}
+}
+
+func (p *vbridgeProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		restorepoint.NewDataSource,
+		licenses.NewDataSource,
+	}
 }
@@ -0,0 +1,99 @@
+package virtualmachine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// validateTopology checks cores splits evenly into the configured sockets
+// or cores_per_socket, and that the two agree if both are set.
+func validateTopology(config resourceModel, resp *resource.ValidateConfigResponse) {
+	if config.Cores.IsUnknown() || config.Sockets.IsUnknown() || config.CoresPerSocket.IsUnknown() {
+		return
+	}
+
+	cores := config.Cores.ValueInt64()
+	if !config.Cores.IsNull() && cores <= 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("cores"), "Invalid configuration",
+			fmt.Sprintf("`cores` must be a positive integer, got: %d", cores))
+		return
+	}
+
+	for _, attribute := range []struct {
+		name  string
+		value types.Int64
+	}{
+		{"sockets", config.Sockets},
+		{"cores_per_socket", config.CoresPerSocket},
+	} {
+		if attribute.value.IsNull() {
+			continue
+		}
+		if value := attribute.value.ValueInt64(); value <= 0 || cores%value != 0 {
+			resp.Diagnostics.AddAttributeError(path.Root(attribute.name), "Invalid configuration",
+				fmt.Sprintf("`%s` must divide `cores` (%d) evenly, got: %d", attribute.name, cores, value))
+		}
+	}
+
+	if !config.Sockets.IsNull() && !config.CoresPerSocket.IsNull() &&
+		config.Sockets.ValueInt64()*config.CoresPerSocket.ValueInt64() != cores {
+		resp.Diagnostics.AddAttributeError(path.Root("sockets"), "Invalid configuration",
+			fmt.Sprintf("`sockets` (%d) times `cores_per_socket` (%d) must equal `cores` (%d)",
+				config.Sockets.ValueInt64(), config.CoresPerSocket.ValueInt64(), cores))
+	}
+}
+
+// planTopology works out sockets and cores_per_socket from whichever is
+// configured. With neither, the VM keeps its cores per socket if cores still
+// splits evenly into it, and otherwise gets a single socket.
+func planTopology(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var cores, sockets, coresPerSocket types.Int64
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cores"), &cores)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("sockets"), &sockets)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cores_per_socket"), &coresPerSocket)...)
+	if resp.Diagnostics.HasError() || cores.IsUnknown() || sockets.IsUnknown() || coresPerSocket.IsUnknown() {
+		return
+	}
+
+	total := cores.ValueInt64()
+	perSocket := total
+	switch {
+	case !coresPerSocket.IsNull():
+		perSocket = coresPerSocket.ValueInt64()
+	case !sockets.IsNull() && sockets.ValueInt64() > 0:
+		perSocket = total / sockets.ValueInt64()
+	case !req.State.Raw.IsNull():
+		var current types.Int64
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cores_per_socket"), &current)...)
+		if current.ValueInt64() > 0 && total%current.ValueInt64() == 0 {
+			perSocket = current.ValueInt64()
+		}
+	}
+
+	// ValidateConfig reports topologies that don't divide evenly.
+	if perSocket <= 0 || total%perSocket != 0 {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("cores_per_socket"), types.Int64Value(perSocket))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sockets"), types.Int64Value(total/perSocket))...)
+}
+
+// updateCPU applies the planned cores and topology. CPUs can be hot added,
+// but removing them or changing the topology needs the VM powered off.
+func (r *Resource) updateCPU(ctx context.Context, vmID string, plan, state resourceModel) error {
+	cores := int(plan.Cores.ValueInt64())
+	coresPerSocket := int(plan.CoresPerSocket.ValueInt64())
+
+	if plan.CoresPerSocket.Equal(state.CoresPerSocket) && plan.Cores.ValueInt64() >= state.Cores.ValueInt64() {
+		return r.client.UpdateCPU(ctx, vmID, cores, coresPerSocket)
+	}
+
+	return r.whilePoweredOff(ctx, vmID, func() error {
+		return r.client.UpdateCPU(ctx, vmID, cores, coresPerSocket)
+	})
+}
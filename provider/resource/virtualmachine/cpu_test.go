@@ -0,0 +1,133 @@
+package virtualmachine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTopology(t *testing.T) {
+	tests := []struct {
+		name           string
+		cores          types.Int64
+		sockets        types.Int64
+		coresPerSocket types.Int64
+		want           []string
+	}{
+		{"cores only", types.Int64Value(4), types.Int64Null(), types.Int64Null(), nil},
+		{"sockets divide cores", types.Int64Value(4), types.Int64Value(2), types.Int64Null(), nil},
+		{"sockets don't divide cores", types.Int64Value(4), types.Int64Value(3), types.Int64Null(), []string{"sockets"}},
+		{"cores_per_socket divides cores", types.Int64Value(6), types.Int64Null(), types.Int64Value(3), nil},
+		{"cores_per_socket doesn't divide cores", types.Int64Value(6), types.Int64Null(), types.Int64Value(4), []string{"cores_per_socket"}},
+		{"zero sockets", types.Int64Value(4), types.Int64Value(0), types.Int64Null(), []string{"sockets"}},
+		{"both agree", types.Int64Value(8), types.Int64Value(2), types.Int64Value(4), nil},
+		{"both disagree", types.Int64Value(8), types.Int64Value(2), types.Int64Value(2), []string{"sockets"}},
+		{"cores not positive", types.Int64Value(0), types.Int64Value(1), types.Int64Null(), []string{"cores"}},
+		{"sockets unknown", types.Int64Value(4), types.Int64Unknown(), types.Int64Value(3), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			config := resourceModel{Cores: tt.cores, Sockets: tt.sockets, CoresPerSocket: tt.coresPerSocket}
+			resp := &resource.ValidateConfigResponse{}
+
+			// When
+			validateTopology(config, resp)
+
+			// Then
+			assert.ElementsMatch(t, tt.want, attributePaths(resp.Diagnostics.Errors()))
+		})
+	}
+}
+
+func TestPlanTopology(t *testing.T) {
+	tests := []struct {
+		name               string
+		config             map[string]attr.Value
+		state              map[string]attr.Value
+		wantSockets        types.Int64
+		wantCoresPerSocket types.Int64
+	}{
+		{
+			name:               "a single socket by default",
+			config:             map[string]attr.Value{"cores": types.Int64Value(4)},
+			wantSockets:        types.Int64Value(1),
+			wantCoresPerSocket: types.Int64Value(4),
+		},
+		{
+			name:               "sockets configured",
+			config:             map[string]attr.Value{"cores": types.Int64Value(4), "sockets": types.Int64Value(2)},
+			wantSockets:        types.Int64Value(2),
+			wantCoresPerSocket: types.Int64Value(2),
+		},
+		{
+			name:               "cores_per_socket configured",
+			config:             map[string]attr.Value{"cores": types.Int64Value(6), "cores_per_socket": types.Int64Value(3)},
+			wantSockets:        types.Int64Value(2),
+			wantCoresPerSocket: types.Int64Value(3),
+		},
+		{
+			name:               "cores per socket kept when cores change",
+			config:             map[string]attr.Value{"cores": types.Int64Value(8)},
+			state:              map[string]attr.Value{"cores": types.Int64Value(4), "sockets": types.Int64Value(2), "cores_per_socket": types.Int64Value(2)},
+			wantSockets:        types.Int64Value(4),
+			wantCoresPerSocket: types.Int64Value(2),
+		},
+		{
+			name:               "a single socket when cores no longer divide",
+			config:             map[string]attr.Value{"cores": types.Int64Value(6)},
+			state:              map[string]attr.Value{"cores": types.Int64Value(4), "sockets": types.Int64Value(1), "cores_per_socket": types.Int64Value(4)},
+			wantSockets:        types.Int64Value(1),
+			wantCoresPerSocket: types.Int64Value(6),
+		},
+		{
+			name:               "uneven topology left to validation",
+			config:             map[string]attr.Value{"cores": types.Int64Value(6), "cores_per_socket": types.Int64Value(4)},
+			wantSockets:        types.Int64Unknown(),
+			wantCoresPerSocket: types.Int64Value(4),
+		},
+		{
+			name:               "cores unknown",
+			config:             map[string]attr.Value{"cores": types.Int64Unknown()},
+			wantSockets:        types.Int64Unknown(),
+			wantCoresPerSocket: types.Int64Unknown(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			ctx := context.Background()
+			planned := map[string]attr.Value{
+				"sockets":          types.Int64Unknown(),
+				"cores_per_socket": types.Int64Unknown(),
+			}
+			for name, value := range tt.config {
+				planned[name] = value
+			}
+			req := resource.ModifyPlanRequest{
+				Config: testConfig(t, tt.config),
+				Plan:   testPlan(t, planned),
+				State:  testState(t, tt.state),
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+
+			// When
+			planTopology(ctx, req, resp)
+
+			// Then
+			var sockets, coresPerSocket types.Int64
+			resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("sockets"), &sockets)...)
+			resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("cores_per_socket"), &coresPerSocket)...)
+			assert.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			assert.Equal(t, tt.wantSockets, sockets)
+			assert.Equal(t, tt.wantCoresPerSocket, coresPerSocket)
+		})
+	}
+}
@@ -1,65 +1,121 @@
 package virtualmachine
 
 import (
-	"fmt"
+	"context"
 	"terraform-provider-vbridge/api"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func Create(d *schema.ResourceData, meta interface{}) error {
-	apiClient := meta.(*api.Client)
-
-	template, templateSet := d.GetOk("template")
-	capacity, capacitySet := d.GetOk("operating_system_disk_capacity")
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if templateSet && capacitySet {
-		return fmt.Errorf("`operating_system_disk_capacity` should not be set when `template` is specified")
-	} else if !templateSet && !capacitySet {
-		return fmt.Errorf("`operating_system_disk_capacity` is required when `template` is not specified")
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	vm := api.VirtualMachine{
-		ClientId:   d.Get("client_id").(int),
-		Name:       d.Get("name").(string),
-		Template:   d.Get("template").(string),
-		GuestOsId:  d.Get("guest_os_id").(string),
-		Cores:      d.Get("cores").(int),
-		MemorySize: d.Get("memory_size").(int),
+		ClientId:       int(plan.ClientId.ValueInt64()),
+		Name:           plan.Name.ValueString(),
+		Template:       plan.Template.ValueString(),
+		GuestOsId:      api.NormalizeGuestOSId(plan.GuestOsId.ValueString()),
+		Cores:          int(plan.Cores.ValueInt64()),
+		CoresPerSocket: int(plan.CoresPerSocket.ValueInt64()),
+		MemorySize:     int(plan.memoryMb() / 1024),
+		MemoryMb:       int(plan.memoryMb()),
 		OperatingSystemDisk: api.VirtualDisk{
-			// Capacity:       d.Get("operating_system_disk_capacity").(int),
-			StorageProfile: d.Get("operating_system_disk_storage_profile").(string),
+			StorageProfile: plan.OperatingSystemDiskStorageProfile.ValueString(),
 		},
-		BackupType: d.Get("backup_type").(string),
+		IsoFile:    plan.IsoFile.ValueString(),
+		BackupType: plan.BackupType.ValueString(),
 		HostingLocation: api.HostingLocation{
-			Id:             d.Get("hosting_location_id").(string),
-			Name:           d.Get("hosting_location_name").(string),
-			DefaultNetwork: d.Get("hosting_location_default_network").(string),
+			Id:             plan.HostingLocationId.ValueString(),
+			Name:           plan.HostingLocationName.ValueString(),
+			DefaultNetwork: plan.HostingLocationDefaultNetwork.ValueString(),
 		},
 		QuoteItem: make(map[string]interface{}), // Initialize with an empty map
 	}
 
-	if templateSet {
-		vm.Template = template.(string)
-	} else {
-		vm.OperatingSystemDisk.Capacity = capacity.(int)
+	if plan.Template.IsNull() {
+		vm.OperatingSystemDisk.Capacity = int(plan.OperatingSystemDiskCapacity.ValueInt64())
+	}
+
+	if !plan.QuoteItem.IsNull() {
+		quoteItem := make(map[string]string)
+		resp.Diagnostics.Append(plan.QuoteItem.ElementsAs(ctx, &quoteItem, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for k, v := range quoteItem {
+			vm.QuoteItem[k] = v
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	vmID, err := r.client.CreateVM(ctx, vm)
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating virtual machine", err.Error())
+		return
+	}
+
+	plan.Id = types.StringValue(vmID)
+	plan.VmId = types.StringValue(vmID)
+
+	// Save the ID straight away so a failed read doesn't orphan the VM.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), plan.Id)...)
+
+	// The provisioning payload has no additional disks, so they are added
+	// once the VM exists.
+	disks := plan.additionalDisks(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, disk := range disks {
+		if _, err := r.client.CreateAdditionalDiskWithComparison(ctx, vmID, disk); err != nil {
+			resp.Diagnostics.AddError("Error creating additional disk", err.Error())
+			return
+		}
 	}
 
-	if v, ok := d.GetOk("iso_file"); ok {
-		vm.IsoFile = v.(string)
+	if !plan.License.IsNull() {
+		if _, err := r.client.AssignLicense(ctx, vmID, plan.License.ValueString()); err != nil {
+			resp.Diagnostics.AddError("Error assigning license", err.Error())
+			return
+		}
 	}
 
-	if v, ok := d.GetOk("quote_item"); ok {
-		vm.QuoteItem = v.(map[string]interface{})
+	if !plan.Annotation.IsNull() || len(plan.TagsAll.Elements()) > 0 {
+		resp.Diagnostics.Append(r.writeAnnotation(ctx, vmID, plan, resourceModel{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
-	vmID, err := apiClient.CreateVM(vm)
+	detailed, err := r.client.GetVMDetailedByID(ctx, vmID)
 	if err != nil {
-		return err
+		resp.Diagnostics.AddError("Error reading virtual machine", err.Error())
+		return
 	}
 
-	d.SetId(vmID)
-	d.Set("vm_id", vmID)
+	plan.setFromVM(detailed)
+
+	snapshots, err := r.listSnapshots(ctx, detailed)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading virtual machine snapshots", err.Error())
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
 
-	return Read(d, meta)
+	plan.setSnapshots(snapshots)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
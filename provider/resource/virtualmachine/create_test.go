@@ -1,51 +1,219 @@
 package virtualmachine_test
 
 import (
-    "testing"
-    "terraform-provider-vbridge/provider"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+	"time"
 
-    "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"localhost-api/mockapi"
 )
 
-// Define the providers to be used in tests
-var testAccProviders = map[string]*schema.Provider{
-    "vbridge": provider.Provider(),
+// testAccVirtualMachineArgs are arguments of the VM in a test configuration,
+// on top of or in place of testAccVirtualMachineDefaults. Strings are quoted,
+// hcl is written as it is and nil leaves a default argument out.
+type testAccVirtualMachineArgs map[string]interface{}
+
+// hcl is an argument value written to a test configuration as it is, such as
+// a map or a reference.
+type hcl string
+
+// testAccVirtualMachineDefaults are the arguments every test VM starts from,
+// in the order they are written.
+var testAccVirtualMachineDefaults = []struct {
+	name  string
+	value interface{}
+}{
+	{"client_id", acctest.ClientId},
+	{"name", "test-vm"},
+	{"template", "Windows2022_Standard_30GB"},
+	{"guest_os_id", "windows2019srv_64Guest"},
+	{"cores", 2},
+	{"memory_size", 6},
+	{"operating_system_disk_storage_profile", "vStorageT1"},
+	{"hosting_location_id", "vcchcres"},
+	{"hosting_location_name", "Christchurch"},
+	{"hosting_location_default_network", "CHC-CUST-SDC-WAN"},
+	{"backup_type", "vBackupDisk"},
 }
 
 // Test configuration
-func testAccVirtualMachineConfig_basic() string {
-    return `
-    provider "vbridge" {
-        api_url    = "https://api.example.com"
-        api_key    = "my-api-key"
-        user_email = "user@example.com"
-    }
-
-    resource "vbridge_virtual_machine1" "vm" {
-        name  = "test-vm"
-        cores = 10
-    }`
+func testAccVirtualMachineConfig(apiURL string, args testAccVirtualMachineArgs, blocks ...string) string {
+	return acctest.ProviderConfig(apiURL) + testAccVirtualMachineResource(args, blocks...)
+}
+
+// testAccVirtualMachineResource is the VM of testAccVirtualMachineConfig, for
+// tests that configure the provider themselves. Arguments that aren't
+// defaults follow them in name order, then the blocks.
+func testAccVirtualMachineResource(args testAccVirtualMachineArgs, blocks ...string) string {
+	var config strings.Builder
+	config.WriteString("\nresource \"vbridge_virtual_machine\" \"vm\" {\n")
+
+	written := make(map[string]bool)
+	writeArgument := func(name string, value interface{}) {
+		written[name] = true
+		switch v := value.(type) {
+		case nil:
+		case hcl:
+			fmt.Fprintf(&config, "  %-37s = %s\n", name, v)
+		case string:
+			fmt.Fprintf(&config, "  %-37s = %q\n", name, v)
+		default:
+			fmt.Fprintf(&config, "  %-37s = %v\n", name, v)
+		}
+	}
+
+	for _, argument := range testAccVirtualMachineDefaults {
+		value, ok := args[argument.name]
+		if !ok {
+			value = argument.value
+		}
+		writeArgument(argument.name, value)
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		if !written[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeArgument(name, args[name])
+	}
+
+	for _, block := range blocks {
+		config.WriteString("\n" + block + "\n")
+	}
+
+	config.WriteString("}\n")
+	return config.String()
+}
+
+// Test for creating, importing and destroying a virtual machine resource
+func TestAccVirtualMachine_basic(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, nil),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineExists(mockAPI.URL, "vbridge_virtual_machine.vm"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "name", "test-vm"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores", "2"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "sockets", "1"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores_per_socket", "2"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "memory_size", "6"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "guest_os_full_name", "Microsoft Windows Server 2019 (64-bit)"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "operating_system_disk_capacity", "30"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "operating_system_disk_storage_profile", "vStorageT1"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "virtual_disks.#", "1"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "virtual_disks.0.storage_profile", "vStorageT1"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "network_devices.#", "1"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "network_devices.0.network_name", "CHC-CUST-SDC-WAN"),
+					resource.TestCheckResourceAttrPair("vbridge_virtual_machine.vm", "vm_id", "vbridge_virtual_machine.vm", "id"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine.vm", "mo_ref"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine.vm", "operating_system_disk_guid"),
+				),
+			},
+			{
+				// WHEN
+				ResourceName:      "vbridge_virtual_machine.vm",
+				ImportState:       true,
+				ImportStateVerify: true,
+
+				// THEN
+				// These are only sent when provisioning and aren't reported back
+				ImportStateVerifyIgnore: []string{
+					"template",
+					"hosting_location_name",
+					"hosting_location_default_network",
+					"timeouts",
+				},
+			},
+		},
+	})
 }
 
-// Test for creating a virtual machine resource
-func TestVirtualMachineCreation(t *testing.T) {
+// Test for creating a virtual machine when the API is slow to report it
+func TestAccVirtualMachine_eventualConsistency(t *testing.T) {
 	// GIVEN
-    resource.Test(t, resource.TestCase{
-        Providers: testAccProviders,
-        Steps: []resource.TestStep{
-            {
+	mockAPI := acctest.NewMockAPIWithOptions(t, mockapi.Options{
+		Faults: mockapi.Faults{
+			Latency:       mockapi.Duration(50 * time.Millisecond),
+			ListDelay:     mockapi.Duration(2 * time.Second),
+			DetailedDelay: mockapi.Duration(8 * time.Second),
+		},
+	})
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
 				// WHEN
-                Config: testAccVirtualMachineConfig_basic(),
+				Config: testAccVirtualMachineConfig(mockAPI.URL, nil),
 
 				// THEN
-                Check: resource.ComposeTestCheckFunc(
-                    resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "name", "test-vm"),
-                    resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores", "2"),
-                ),
-            },
-        },
-    })
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineExists(mockAPI.URL, "vbridge_virtual_machine.vm"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine.vm", "mo_ref"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "virtual_disks.#", "1"),
+				),
+			},
+		},
+	})
 }
 
+// testAccCheckVirtualMachineExists checks the VM in state exists in the API.
+func testAccCheckVirtualMachineExists(apiURL, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
 
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.GetVMDetailedByID(context.Background(), rs.Primary.ID)
+		return err
+	}
+}
+
+// testAccCheckVirtualMachineDestroy checks every VM in state has been deleted.
+func testAccCheckVirtualMachineDestroy(apiURL string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "vbridge_virtual_machine" {
+				continue
+			}
+
+			if _, err := client.GetVMDetailedByID(context.Background(), rs.Primary.ID); err == nil {
+				return fmt.Errorf("VM %s still exists", rs.Primary.ID)
+			}
+		}
+
+		return nil
+	}
+}
@@ -1,34 +1,50 @@
 package virtualmachine
 
 import (
-	"fmt"
+	"context"
 	"terraform-provider-vbridge/api"
-	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 )
 
-func Delete(d *schema.ResourceData, meta interface{}) error {
-	apiClient := meta.(*api.Client)
-	vmID := d.Id()
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	vm, err := apiClient.GetVMDetailedByID(vmID)
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	vmID := state.Id.ValueString()
+	vm, err := r.client.GetVMDetailedByID(ctx, vmID)
 	if err != nil {
-		return err
+		resp.Diagnostics.AddError("Error reading virtual machine", err.Error())
+		return
 	}
 
-	err = apiClient.PowerOffVM(vmID)
+	err = r.client.PowerOffVM(ctx, vmID)
 	if err != nil {
-		return fmt.Errorf("error shutting down VM: %w", err)
+		resp.Diagnostics.AddError("Error shutting down VM", err.Error())
+		return
 	}
 
-	time.Sleep(10 * time.Second)
+	// The API refuses to delete a VM that is still running.
+	if err := r.client.WaitForPowerState(ctx, vmID, api.PowerStateOff); err != nil {
+		resp.Diagnostics.AddError("Error shutting down VM", err.Error())
+		return
+	}
 
-	err = apiClient.DeleteVM(vmID, vm.Specification.MoRef)
+	err = r.client.DeleteVM(ctx, vmID, vm.Specification.MoRef)
 	if err != nil {
-		return fmt.Errorf("error deleting VM: %w", err)
+		resp.Diagnostics.AddError("Error deleting VM", err.Error())
+		return
 	}
-
-	d.SetId("")
-	return nil
 }
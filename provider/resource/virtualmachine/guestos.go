@@ -0,0 +1,99 @@
+package virtualmachine
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// guestOSType is the type of guest_os_id. Its values are semantically equal
+// when they name the same guest OS, so writing the vSphere identifier or the
+// name the API reports doesn't show up as a diff.
+type guestOSType struct {
+	basetypes.StringType
+}
+
+var _ basetypes.StringTypable = guestOSType{}
+
+func (t guestOSType) Equal(o attr.Type) bool {
+	other, ok := o.(guestOSType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t guestOSType) String() string {
+	return "guestOSType"
+}
+
+func (t guestOSType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return guestOSValue{StringValue: in}, nil
+}
+
+func (t guestOSType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	return guestOSValue{StringValue: stringValue}, nil
+}
+
+func (t guestOSType) ValueType(ctx context.Context) attr.Value {
+	return guestOSValue{}
+}
+
+// guestOSValue holds a vSphere guest OS identifier or name.
+type guestOSValue struct {
+	basetypes.StringValue
+}
+
+var _ basetypes.StringValuableWithSemanticEquals = guestOSValue{}
+
+func newGuestOSValue(guestOS string) guestOSValue {
+	return guestOSValue{StringValue: basetypes.NewStringValue(guestOS)}
+}
+
+func (v guestOSValue) Equal(o attr.Value) bool {
+	other, ok := o.(guestOSValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v guestOSValue) Type(ctx context.Context) attr.Type {
+	return guestOSType{}
+}
+
+// Same reports whether v and other name the same guest OS.
+func (v guestOSValue) Same(other guestOSValue) bool {
+	if v.IsNull() || v.IsUnknown() || other.IsNull() || other.IsUnknown() {
+		return v.Equal(other)
+	}
+	return api.SameGuestOS(v.ValueString(), other.ValueString())
+}
+
+func (v guestOSValue) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(guestOSValue)
+	if !ok {
+		diags.AddError("Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got value type %T. Please report this to the provider developers.", v, newValuable))
+		return false, diags
+	}
+
+	return v.Same(newValue), diags
+}
@@ -0,0 +1,151 @@
+package virtualmachine
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// memoryMb returns the VM's memory in MB, from whichever of memory_size or
+// memory_mb is set.
+func (m resourceModel) memoryMb() int64 {
+	if !m.MemoryMb.IsNull() {
+		return m.MemoryMb.ValueInt64()
+	}
+	return m.MemorySize.ValueInt64() * 1024
+}
+
+// setMemoryFromVM sets memory in the unit it was configured in. Imported
+// VMs get memory_size unless their memory isn't a whole number of GB, as
+// does a VM resized outside Terraform, so the plan shows the drift. A
+// configured memory_mb is kept while the VM's memory matches it, since
+// responses that only report whole GB can't show a size within a GB.
+func (m *resourceModel) setMemoryFromVM(vm api.VirtualMachine) {
+	if !m.MemoryMb.IsNull() && vm.Specification.HasMemoryMb(int(m.MemoryMb.ValueInt64())) {
+		m.MemorySize = types.Int64Null()
+		return
+	}
+
+	memoryMb := int64(vm.Specification.MemorySizeMb())
+	if m.MemoryMb.IsNull() && memoryMb%1024 == 0 {
+		m.MemorySize = types.Int64Value(memoryMb / 1024)
+		return
+	}
+
+	m.MemorySize = types.Int64Null()
+	m.MemoryMb = types.Int64Value(memoryMb)
+}
+
+// validateMemory checks exactly one of memory_size and memory_mb is set, and
+// that it is positive. Limits depend on the hosting location and are checked
+// by planMemory.
+func validateMemory(config resourceModel, resp *resource.ValidateConfigResponse) {
+	if config.MemorySize.IsUnknown() || config.MemoryMb.IsUnknown() {
+		return
+	}
+
+	switch {
+	case !config.MemorySize.IsNull() && !config.MemoryMb.IsNull():
+		resp.Diagnostics.AddAttributeError(path.Root("memory_mb"), "Conflicting configuration",
+			"`memory_mb` should not be set when `memory_size` is specified")
+	case config.MemorySize.IsNull() && config.MemoryMb.IsNull():
+		resp.Diagnostics.AddAttributeError(path.Root("memory_size"), "Missing configuration",
+			"One of `memory_size` or `memory_mb` is required")
+	case !config.MemorySize.IsNull() && config.MemorySize.ValueInt64() <= 0:
+		resp.Diagnostics.AddAttributeError(path.Root("memory_size"), "Invalid configuration",
+			fmt.Sprintf("`memory_size` must be a positive integer, got: %d", config.MemorySize.ValueInt64()))
+	case !config.MemoryMb.IsNull() && config.MemoryMb.ValueInt64() <= 0:
+		resp.Diagnostics.AddAttributeError(path.Root("memory_mb"), "Invalid configuration",
+			fmt.Sprintf("`memory_mb` must be a positive integer, got: %d", config.MemoryMb.ValueInt64()))
+	}
+}
+
+// replaceOnMemoryResize replaces the VM on a memory change if
+// replace_on_resize is planned true.
+func replaceOnMemoryResize(ctx context.Context, req planmodifier.Int64Request, resp *int64planmodifier.RequiresReplaceIfFuncResponse) {
+	var replace types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("replace_on_resize"), &replace)...)
+	resized, diags := memoryResized(ctx, req.Plan, req.State)
+	resp.Diagnostics.Append(diags...)
+	resp.RequiresReplace = replace.ValueBool() && resized
+}
+
+// memoryResized reports whether the planned memory differs from the VM's.
+// Switching between memory_size and memory_mb with the same total isn't a
+// change.
+func memoryResized(ctx context.Context, planned tfsdk.Plan, current tfsdk.State) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var plan, state resourceModel
+	diags.Append(planned.GetAttribute(ctx, path.Root("memory_size"), &plan.MemorySize)...)
+	diags.Append(planned.GetAttribute(ctx, path.Root("memory_mb"), &plan.MemoryMb)...)
+	diags.Append(current.GetAttribute(ctx, path.Root("memory_size"), &state.MemorySize)...)
+	diags.Append(current.GetAttribute(ctx, path.Root("memory_mb"), &state.MemoryMb)...)
+	if diags.HasError() || plan.MemorySize.IsUnknown() || plan.MemoryMb.IsUnknown() {
+		return false, diags
+	}
+
+	return plan.memoryMb() != state.memoryMb(), diags
+}
+
+// planMemory checks the planned memory against the limits of the planned
+// hosting location. It only does so when either changes, so VMs sized before
+// a limit was introduced can still be planned. If the limits can't be
+// fetched, the API checks the size when it is applied.
+func (r *Resource) planMemory(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var plan resourceModel
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("memory_size"), &plan.MemorySize)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("memory_mb"), &plan.MemoryMb)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("hosting_location_id"), &plan.HostingLocationId)...)
+	if resp.Diagnostics.HasError() || plan.MemorySize.IsUnknown() || plan.MemoryMb.IsUnknown() || plan.HostingLocationId.IsUnknown() {
+		return
+	}
+	if plan.MemorySize.IsNull() && plan.MemoryMb.IsNull() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state resourceModel
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("memory_size"), &state.MemorySize)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("memory_mb"), &state.MemoryMb)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("hosting_location_id"), &state.HostingLocationId)...)
+		if resp.Diagnostics.HasError() || (plan.memoryMb() == state.memoryMb() && plan.HostingLocationId.Equal(state.HostingLocationId)) {
+			return
+		}
+	}
+
+	limits, err := r.client.GetHostingLocationLimits(ctx, plan.HostingLocationId.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddWarning("Unable to check memory limits", err.Error())
+		return
+	}
+
+	attribute := "memory_size"
+	if !plan.MemoryMb.IsNull() {
+		attribute = "memory_mb"
+	}
+	if err := limits.CheckMemory(int(plan.memoryMb())); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root(attribute), "Invalid memory size", err.Error())
+	}
+}
+
+// updateMemory applies the planned memory. Memory can be hot added, but
+// removing it needs the VM powered off.
+func (r *Resource) updateMemory(ctx context.Context, vmID string, plan, state resourceModel) error {
+	memoryMb := int(plan.memoryMb())
+
+	if plan.memoryMb() >= state.memoryMb() {
+		return r.client.UpdateMemory(ctx, vmID, memoryMb)
+	}
+
+	return r.whilePoweredOff(ctx, vmID, func() error {
+		return r.client.UpdateMemory(ctx, vmID, memoryMb)
+	})
+}
@@ -0,0 +1,186 @@
+package virtualmachine
+
+import (
+	"context"
+	"terraform-provider-vbridge/api"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMemory(t *testing.T) {
+	tests := []struct {
+		name       string
+		memorySize types.Int64
+		memoryMb   types.Int64
+		want       []string
+	}{
+		{"memory_size", types.Int64Value(6), types.Int64Null(), nil},
+		{"memory_mb", types.Int64Null(), types.Int64Value(1536), nil},
+		{"both", types.Int64Value(2), types.Int64Value(2048), []string{"memory_mb"}},
+		{"neither", types.Int64Null(), types.Int64Null(), []string{"memory_size"}},
+		{"memory_size not positive", types.Int64Value(0), types.Int64Null(), []string{"memory_size"}},
+		{"memory_mb not positive", types.Int64Null(), types.Int64Value(-512), []string{"memory_mb"}},
+		{"memory_mb unknown", types.Int64Null(), types.Int64Unknown(), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			config := resourceModel{MemorySize: tt.memorySize, MemoryMb: tt.memoryMb}
+			resp := &resource.ValidateConfigResponse{}
+
+			// When
+			validateMemory(config, resp)
+
+			// Then
+			assert.ElementsMatch(t, tt.want, attributePaths(resp.Diagnostics.Errors()))
+		})
+	}
+}
+
+func TestSetMemoryFromVM(t *testing.T) {
+	tests := []struct {
+		name           string
+		memorySize     types.Int64
+		memoryMb       types.Int64
+		specification  api.Specification
+		wantMemorySize types.Int64
+		wantMemoryMb   types.Int64
+	}{
+		{
+			name:           "memory_size kept",
+			memorySize:     types.Int64Value(6),
+			memoryMb:       types.Int64Null(),
+			specification:  api.Specification{MemoryGb: 6, MemoryMb: 6144},
+			wantMemorySize: types.Int64Value(6),
+			wantMemoryMb:   types.Int64Null(),
+		},
+		{
+			name:           "memory_size resized outside Terraform",
+			memorySize:     types.Int64Value(6),
+			memoryMb:       types.Int64Null(),
+			specification:  api.Specification{MemoryGb: 8},
+			wantMemorySize: types.Int64Value(8),
+			wantMemoryMb:   types.Int64Null(),
+		},
+		{
+			name:           "memory_size resized outside Terraform within a GB",
+			memorySize:     types.Int64Value(2),
+			memoryMb:       types.Int64Null(),
+			specification:  api.Specification{MemoryGb: 2, MemoryMb: 2560},
+			wantMemorySize: types.Int64Null(),
+			wantMemoryMb:   types.Int64Value(2560),
+		},
+		{
+			name:           "memory_mb kept",
+			memorySize:     types.Int64Null(),
+			memoryMb:       types.Int64Value(1536),
+			specification:  api.Specification{MemoryGb: 1, MemoryMb: 1536},
+			wantMemorySize: types.Int64Null(),
+			wantMemoryMb:   types.Int64Value(1536),
+		},
+		{
+			name:           "memory_mb kept when only whole GB are reported",
+			memorySize:     types.Int64Null(),
+			memoryMb:       types.Int64Value(1536),
+			specification:  api.Specification{MemoryGb: 2},
+			wantMemorySize: types.Int64Null(),
+			wantMemoryMb:   types.Int64Value(1536),
+		},
+		{
+			name:           "memory_mb resized outside Terraform",
+			memorySize:     types.Int64Null(),
+			memoryMb:       types.Int64Value(1536),
+			specification:  api.Specification{MemoryGb: 4, MemoryMb: 4096},
+			wantMemorySize: types.Int64Null(),
+			wantMemoryMb:   types.Int64Value(4096),
+		},
+		{
+			name:           "imported with whole GB",
+			memorySize:     types.Int64Null(),
+			memoryMb:       types.Int64Null(),
+			specification:  api.Specification{MemoryGb: 4},
+			wantMemorySize: types.Int64Value(4),
+			wantMemoryMb:   types.Int64Null(),
+		},
+		{
+			name:           "imported within a GB",
+			memorySize:     types.Int64Null(),
+			memoryMb:       types.Int64Null(),
+			specification:  api.Specification{MemoryGb: 1, MemoryMb: 1536},
+			wantMemorySize: types.Int64Null(),
+			wantMemoryMb:   types.Int64Value(1536),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			model := resourceModel{MemorySize: tt.memorySize, MemoryMb: tt.memoryMb}
+
+			// When
+			model.setMemoryFromVM(api.VirtualMachine{Specification: tt.specification})
+
+			// Then
+			assert.Equal(t, tt.wantMemorySize, model.MemorySize)
+			assert.Equal(t, tt.wantMemoryMb, model.MemoryMb)
+		})
+	}
+}
+
+func TestMemoryResized(t *testing.T) {
+	tests := []struct {
+		name  string
+		state map[string]attr.Value
+		plan  map[string]attr.Value
+		want  bool
+	}{
+		{
+			name:  "same memory_size",
+			state: map[string]attr.Value{"memory_size": types.Int64Value(2)},
+			plan:  map[string]attr.Value{"memory_size": types.Int64Value(2)},
+		},
+		{
+			name:  "memory_size changed",
+			state: map[string]attr.Value{"memory_size": types.Int64Value(2)},
+			plan:  map[string]attr.Value{"memory_size": types.Int64Value(4)},
+			want:  true,
+		},
+		{
+			name:  "same size in memory_mb",
+			state: map[string]attr.Value{"memory_size": types.Int64Value(2)},
+			plan:  map[string]attr.Value{"memory_mb": types.Int64Value(2048)},
+		},
+		{
+			name:  "same size in memory_size",
+			state: map[string]attr.Value{"memory_mb": types.Int64Value(2048)},
+			plan:  map[string]attr.Value{"memory_size": types.Int64Value(2)},
+		},
+		{
+			name:  "memory_mb changed within a GB",
+			state: map[string]attr.Value{"memory_mb": types.Int64Value(1536)},
+			plan:  map[string]attr.Value{"memory_mb": types.Int64Value(1792)},
+			want:  true,
+		},
+		{
+			name:  "memory_mb unknown",
+			state: map[string]attr.Value{"memory_mb": types.Int64Value(1536)},
+			plan:  map[string]attr.Value{"memory_mb": types.Int64Unknown()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			resized, diags := memoryResized(context.Background(), testPlan(t, tt.plan), testState(t, tt.state))
+
+			// Then
+			assert.False(t, diags.HasError(), "%v", diags)
+			assert.Equal(t, tt.want, resized)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package virtualmachine
+
+import (
+	"context"
+	"terraform-provider-vbridge/api"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type resourceModel struct {
+	Id                                types.String   `tfsdk:"id"`
+	ClientId                          types.Int64    `tfsdk:"client_id"`
+	Name                              types.String   `tfsdk:"name"`
+	ReplaceOnRename                   types.Bool     `tfsdk:"replace_on_rename"`
+	Template                          types.String   `tfsdk:"template"`
+	GuestOsId                         guestOSValue   `tfsdk:"guest_os_id"`
+	ReplaceOnGuestOSChange            types.Bool     `tfsdk:"replace_on_guest_os_change"`
+	GuestOsFullName                   types.String   `tfsdk:"guest_os_full_name"`
+	Cores                             types.Int64    `tfsdk:"cores"`
+	Sockets                           types.Int64    `tfsdk:"sockets"`
+	CoresPerSocket                    types.Int64    `tfsdk:"cores_per_socket"`
+	ReplaceOnResize                   types.Bool     `tfsdk:"replace_on_resize"`
+	MemorySize                        types.Int64    `tfsdk:"memory_size"`
+	MemoryMb                          types.Int64    `tfsdk:"memory_mb"`
+	OperatingSystemDiskGuid           types.String   `tfsdk:"operating_system_disk_guid"`
+	OperatingSystemDiskCapacity       types.Int64    `tfsdk:"operating_system_disk_capacity"`
+	OperatingSystemDiskStorageProfile types.String   `tfsdk:"operating_system_disk_storage_profile"`
+	AdditionalDisks                   types.List     `tfsdk:"additional_disks"`
+	IsoFile                           types.String   `tfsdk:"iso_file"`
+	QuoteItem                         types.Map      `tfsdk:"quote_item"`
+	HostingLocationId                 types.String   `tfsdk:"hosting_location_id"`
+	ReplaceOnMigrate                  types.Bool     `tfsdk:"replace_on_migrate"`
+	HostingLocationName               types.String   `tfsdk:"hosting_location_name"`
+	HostingLocationDefaultNetwork     types.String   `tfsdk:"hosting_location_default_network"`
+	NetworkMapping                    types.Map      `tfsdk:"network_mapping"`
+	BackupType                        types.String   `tfsdk:"backup_type"`
+	VmId                              types.String   `tfsdk:"vm_id"`
+	MoRef                             types.String   `tfsdk:"mo_ref"`
+	VirtualDisks                      types.List     `tfsdk:"virtual_disks"`
+	NetworkDevices                    types.List     `tfsdk:"network_devices"`
+	Snapshots                         types.List     `tfsdk:"snapshots"`
+	License                           types.String   `tfsdk:"license"`
+	LicenseId                         types.Int64    `tfsdk:"license_id"`
+	LicenseKey                        types.String   `tfsdk:"license_key"`
+	Annotation                        types.String   `tfsdk:"annotation"`
+	Tags                              types.Map      `tfsdk:"tags"`
+	TagsAll                           types.Map      `tfsdk:"tags_all"`
+	Timeouts                          timeouts.Value `tfsdk:"timeouts"`
+}
+
+type additionalDiskModel struct {
+	Capacity       types.Int64  `tfsdk:"capacity"`
+	StorageProfile types.String `tfsdk:"storage_profile"`
+}
+
+var virtualDiskAttrTypes = map[string]attr.Type{
+	"mo_ref":          types.StringType,
+	"name":            types.StringType,
+	"capacity":        types.Int64Type,
+	"storage_profile": types.StringType,
+}
+
+var networkDeviceAttrTypes = map[string]attr.Type{
+	"name":         types.StringType,
+	"mo_ref":       types.StringType,
+	"network_name": types.StringType,
+	"network_id":   types.StringType,
+	"mac_address":  types.StringType,
+	"connected":    types.BoolType,
+}
+
+var snapshotAttrTypes = map[string]attr.Type{
+	"id":          types.StringType,
+	"name":        types.StringType,
+	"description": types.StringType,
+	"created_on":  types.StringType,
+}
+
+// setFromVM copies the attributes reported by the detailed endpoint into the
+// model. Attributes the API doesn't return, such as template, are left as
+// they were planned.
+func (m *resourceModel) setFromVM(vm api.VirtualMachine) {
+	m.ClientId = types.Int64Value(int64(vm.ClientId))
+	m.Name = types.StringValue(vm.Name)
+	m.Cores = types.Int64Value(int64(vm.Specification.TotalCores()))
+	if vm.Specification.Sockets > 0 {
+		m.Sockets = types.Int64Value(int64(vm.Specification.Sockets))
+		m.CoresPerSocket = types.Int64Value(int64(vm.Specification.Cores))
+	}
+	m.setMemoryFromVM(vm)
+	m.BackupType = types.StringValue(vm.Specification.BackupType)
+	m.HostingLocationId = types.StringValue(vm.Specification.HostingLocationId)
+
+	// The detailed endpoint reports the guest OS by name rather than by the
+	// ID it was created with. Names missing from the mapping leave the
+	// configured value alone.
+	if vm.GuestOsId != "" {
+		m.GuestOsId = newGuestOSValue(vm.GuestOsId)
+	} else if guestOsId, ok := api.GuestOSIdFromName(vm.GuestOS); ok {
+		m.GuestOsId = newGuestOSValue(guestOsId)
+	}
+
+	if len(vm.Specification.VirtualDisks) > 0 {
+		osDisk := vm.Specification.VirtualDisks[0]
+		m.OperatingSystemDiskCapacity = types.Int64Value(int64(osDisk.Capacity))
+		m.OperatingSystemDiskStorageProfile = types.StringValue(osDisk.Tier)
+	}
+
+	// Only track the license if it is managed here, so licenses assigned
+	// in the portal aren't removed.
+	if !m.License.IsNull() {
+		m.License = types.StringNull()
+		if license := vm.AssignedLicense(); license != nil {
+			m.License = types.StringValue(license.Name)
+		}
+	}
+
+	// Not stored by the API, so imported VMs get the default.
+	if m.ReplaceOnRename.IsNull() {
+		m.ReplaceOnRename = types.BoolValue(false)
+	}
+	if m.ReplaceOnGuestOSChange.IsNull() {
+		m.ReplaceOnGuestOSChange = types.BoolValue(false)
+	}
+	if m.ReplaceOnMigrate.IsNull() {
+		m.ReplaceOnMigrate = types.BoolValue(false)
+	}
+	if m.ReplaceOnResize.IsNull() {
+		m.ReplaceOnResize = types.BoolValue(false)
+	}
+
+	m.setTagsFromVM(vm)
+	m.setComputedFromVM(vm)
+}
+
+// setComputedFromVM copies only the attributes Terraform can't plan. It is
+// used after an update, where configured values must be kept as planned.
+func (m *resourceModel) setComputedFromVM(vm api.VirtualMachine) {
+	m.MoRef = types.StringValue(vm.Specification.MoRef)
+	m.VmId = types.StringValue(vm.Id.String())
+
+	m.GuestOsFullName = types.StringValue(vm.GuestOS)
+	if vm.GuestOS == "" {
+		fullName, _ := api.GuestOSFullName(api.NormalizeGuestOSId(m.GuestOsId.ValueString()))
+		m.GuestOsFullName = types.StringValue(fullName)
+	}
+
+	if len(vm.Specification.VirtualDisks) > 0 {
+		osDisk := vm.Specification.VirtualDisks[0]
+		m.OperatingSystemDiskGuid = types.StringValue(osDisk.MoRef)
+		if m.OperatingSystemDiskCapacity.IsUnknown() {
+			m.OperatingSystemDiskCapacity = types.Int64Value(int64(osDisk.Capacity))
+		}
+	}
+
+	disks := make([]attr.Value, 0, len(vm.Specification.VirtualDisks))
+	for _, disk := range vm.Specification.VirtualDisks {
+		disks = append(disks, types.ObjectValueMust(virtualDiskAttrTypes, map[string]attr.Value{
+			"mo_ref":          types.StringValue(disk.MoRef),
+			"name":            types.StringValue(disk.Name),
+			"capacity":        types.Int64Value(int64(disk.Capacity)),
+			"storage_profile": types.StringValue(disk.Tier),
+		}))
+	}
+	m.VirtualDisks = types.ListValueMust(types.ObjectType{AttrTypes: virtualDiskAttrTypes}, disks)
+
+	nics := make([]attr.Value, 0, len(vm.Specification.NetworkDevices))
+	for _, nic := range vm.Specification.NetworkDevices {
+		nics = append(nics, types.ObjectValueMust(networkDeviceAttrTypes, map[string]attr.Value{
+			"name":         types.StringValue(nic.Name),
+			"mo_ref":       types.StringValue(nic.MoRef),
+			"network_name": types.StringValue(nic.NetworkName),
+			"network_id":   types.StringValue(nic.NetworkId),
+			"mac_address":  types.StringValue(nic.MacAddress),
+			"connected":    types.BoolValue(nic.Connected),
+		}))
+	}
+	m.NetworkDevices = types.ListValueMust(types.ObjectType{AttrTypes: networkDeviceAttrTypes}, nics)
+
+	m.LicenseId = types.Int64Null()
+	m.LicenseKey = types.StringNull()
+	if license := vm.AssignedLicense(); license != nil {
+		m.LicenseId = types.Int64Value(int64(license.LicenseId))
+		m.LicenseKey = types.StringValue(license.LicenseKey)
+	}
+}
+
+func (m *resourceModel) setSnapshots(snapshots []api.Snapshot) {
+	values := make([]attr.Value, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		values = append(values, types.ObjectValueMust(snapshotAttrTypes, map[string]attr.Value{
+			"id":          types.StringValue(snapshot.Id),
+			"name":        types.StringValue(snapshot.Name),
+			"description": types.StringValue(snapshot.Description),
+			"created_on":  types.StringValue(snapshot.CreatedOn.Format(time.RFC3339)),
+		}))
+	}
+	m.Snapshots = types.ListValueMust(types.ObjectType{AttrTypes: snapshotAttrTypes}, values)
+}
+
+// additionalDisks returns the disks in additional_disks.
+func (m resourceModel) additionalDisks(ctx context.Context, diags *diag.Diagnostics) []api.VirtualDisk {
+	if m.AdditionalDisks.IsNull() || m.AdditionalDisks.IsUnknown() {
+		return nil
+	}
+
+	var models []additionalDiskModel
+	diags.Append(m.AdditionalDisks.ElementsAs(ctx, &models, false)...)
+
+	disks := make([]api.VirtualDisk, 0, len(models))
+	for _, disk := range models {
+		disks = append(disks, api.VirtualDisk{
+			Capacity:       int(disk.Capacity.ValueInt64()),
+			StorageProfile: disk.StorageProfile.ValueString(),
+		})
+	}
+	return disks
+}
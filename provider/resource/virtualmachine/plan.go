@@ -0,0 +1,238 @@
+package virtualmachine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// replacementReasons explains why changing each attribute marked with
+// RequiresReplace in the schema replaces the VM. Attributes that follow a
+// migration don't replace it when hosting_location_id changes too.
+var replacementReasons = []struct {
+	attribute      string
+	reason         string
+	followsMigrate bool
+}{
+	{"client_id", "VMs can't be moved between clients.", false},
+	{"template", "The template is only used when the VM is provisioned.", false},
+	{"hosting_location_name", "It only changes along with `hosting_location_id`, which migrates the VM.", true},
+	{"hosting_location_default_network", "Outside a migration, the default network is only used when the VM is provisioned.", true},
+	{"operating_system_disk_storage_profile", "The operating system disk can't be moved to another storage profile in place.", false},
+	{"backup_type", "The backup type is only set when the VM is provisioned.", false},
+	{"iso_file", "The ISO file is only mounted when the VM is provisioned.", false},
+	{"quote_item", "The quote item is only sent when the VM is provisioned.", false},
+	{"additional_disks", "These disks are only added when the VM is provisioned; use `vbridge_virtual_machine_additionaldisk` for disks managed afterwards.", false},
+}
+
+// replacementSwitches are the attributes that are changed in place unless
+// the switch named with them is set, in which case they replace the VM.
+// Attributes whose change isn't just a different value say how to tell;
+// only the one that is set is warned about.
+var replacementSwitches = []struct {
+	attribute string
+	flag      string
+	changed   func(ctx context.Context, plan tfsdk.Plan, state tfsdk.State) (bool, diag.Diagnostics)
+}{
+	{"name", "replace_on_rename", nil},
+	{"guest_os_id", "replace_on_guest_os_change", nil},
+	{"hosting_location_id", "replace_on_migrate", nil},
+	{"cores", "replace_on_resize", nil},
+	{"sockets", "replace_on_resize", nil},
+	{"cores_per_socket", "replace_on_resize", nil},
+	{"memory_size", "replace_on_resize", memoryResized},
+	{"memory_mb", "replace_on_resize", memoryResized},
+}
+
+func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	r.planTagsAll(ctx, req, resp)
+	planTopology(ctx, req, resp)
+	r.planMemory(ctx, req, resp)
+
+	if !req.State.Raw.IsNull() {
+		planGuestOSFullName(ctx, req, resp)
+		planOperatingSystemDisk(ctx, req, resp)
+		migrating := planMigration(ctx, req, resp)
+		planComputedChanges(ctx, req, resp, migrating)
+		explainReplacement(ctx, req, resp, migrating)
+	}
+}
+
+// planMigration reports whether hosting_location_id changes, which migrates
+// the VM. The migration can take a while, so the plan warns about it, and
+// the VM's MoRef is unknown since it can change between sites. With
+// replace_on_migrate set the VM is replaced instead, which
+// explainReplacement warns about, but the hosting location's other
+// attributes still change along with it.
+func planMigration(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) bool {
+	var location, currentLocation types.String
+	var replace types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("hosting_location_id"), &location)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("hosting_location_id"), &currentLocation)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("replace_on_migrate"), &replace)...)
+	if resp.Diagnostics.HasError() || location.Equal(currentLocation) {
+		return false
+	}
+	if replace.ValueBool() {
+		return true
+	}
+
+	resp.Diagnostics.AddAttributeWarning(path.Root("hosting_location_id"), "Virtual machine will be migrated",
+		fmt.Sprintf("Changing `hosting_location_id` migrates the VM from %s to %s. Its NICs are moved to the networks in `network_mapping`, or to `hosting_location_default_network`.",
+			currentLocation.ValueString(), location.ValueString()))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("mo_ref"), types.StringUnknown())...)
+	return true
+}
+
+// computedDependencies lists the computed attributes that otherwise keep
+// their state value, with the configured attributes whose change alters
+// them. They are unknown in the plan when any of those change.
+var computedDependencies = []struct {
+	attribute      string
+	unknown        attr.Value
+	dependsOn      []string
+	followsMigrate bool
+}{
+	{"license_id", types.Int64Unknown(), []string{"license"}, false},
+	{"license_key", types.StringUnknown(), []string{"license"}, false},
+	{"virtual_disks", types.ListUnknown(types.ObjectType{AttrTypes: virtualDiskAttrTypes}),
+		[]string{"operating_system_disk_capacity", "operating_system_disk_storage_profile"}, true},
+	{"network_devices", types.ListUnknown(types.ObjectType{AttrTypes: networkDeviceAttrTypes}), nil, true},
+}
+
+// planComputedChanges marks the computed attributes in computedDependencies
+// unknown when what they depend on changes, or the VM is migrated.
+func planComputedChanges(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, migrating bool) {
+	for _, computed := range computedDependencies {
+		changed := migrating && computed.followsMigrate
+		for _, attribute := range computed.dependsOn {
+			var planned, current attr.Value
+			resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root(attribute), &planned)...)
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(attribute), &current)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			changed = changed || !planned.Equal(current)
+		}
+
+		if changed {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(computed.attribute), computed.unknown)...)
+		}
+	}
+}
+
+// planGuestOSFullName keeps guest_os_full_name while guest_os_id names the
+// same guest OS, so it is only unknown when the guest OS changes.
+func planGuestOSFullName(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var planned, current guestOSValue
+	var fullName types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("guest_os_id"), &planned)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("guest_os_id"), &current)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("guest_os_full_name"), &fullName)...)
+	if resp.Diagnostics.HasError() || !planned.Same(current) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("guest_os_full_name"), fullName)...)
+}
+
+// planOperatingSystemDisk rejects shrinking the operating system disk, which
+// can only be extended.
+func planOperatingSystemDisk(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var capacity, currentCapacity types.Int64
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("operating_system_disk_capacity"), &capacity)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("operating_system_disk_capacity"), &currentCapacity)...)
+	if resp.Diagnostics.HasError() || capacity.IsUnknown() || capacity.IsNull() || currentCapacity.IsNull() {
+		return
+	}
+
+	if capacity.ValueInt64() < currentCapacity.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(path.Root("operating_system_disk_capacity"), "Invalid disk size",
+			fmt.Sprintf("The operating system disk can't be shrunk from %d GB to %d GB.", currentCapacity.ValueInt64(), capacity.ValueInt64()))
+	}
+}
+
+// explainReplacement warns about each change that replaces the VM, since the
+// plan itself only marks the attribute. Attributes that weren't known
+// before, such as template on an imported VM, don't replace it.
+func explainReplacement(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, migrating bool) {
+	for _, replacement := range replacementReasons {
+		if migrating && replacement.followsMigrate {
+			continue
+		}
+
+		attributePath := path.Root(replacement.attribute)
+
+		var planned, current attr.Value
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, attributePath, &planned)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, attributePath, &current)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if current.IsNull() || planned.Equal(current) {
+			continue
+		}
+
+		resp.Diagnostics.AddAttributeWarning(attributePath, "Virtual machine will be replaced",
+			fmt.Sprintf("Changing `%s` replaces the VM. %s", replacement.attribute, replacement.reason))
+	}
+
+	for _, replacement := range replacementSwitches {
+		attributePath := path.Root(replacement.attribute)
+
+		var planned, current attr.Value
+		var replace types.Bool
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, attributePath, &planned)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, attributePath, &current)...)
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root(replacement.flag), &replace)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		changed := !current.IsNull() && !planned.IsUnknown() && !sameValue(ctx, planned, current)
+		if replacement.changed != nil {
+			resized, diags := replacement.changed(ctx, req.Plan, req.State)
+			resp.Diagnostics.Append(diags...)
+			changed = resized && !planned.IsNull()
+		}
+
+		if !replace.ValueBool() || !changed {
+			continue
+		}
+
+		resp.Diagnostics.AddAttributeWarning(attributePath, "Virtual machine will be replaced",
+			fmt.Sprintf("Changing `%s` replaces the VM because `%s` is set.", replacement.attribute, replacement.flag))
+	}
+}
+
+// sameValue reports whether planned and current are equal, or semantically
+// equal for types such as guest_os_id's that define it.
+func sameValue(ctx context.Context, planned, current attr.Value) bool {
+	if planned.Equal(current) {
+		return true
+	}
+
+	semantic, ok := planned.(basetypes.StringValuableWithSemanticEquals)
+	if !ok {
+		return false
+	}
+	currentString, ok := current.(basetypes.StringValuable)
+	if !ok {
+		return false
+	}
+
+	same, diags := semantic.StringSemanticEquals(ctx, currentString)
+	return same && !diags.HasError()
+}
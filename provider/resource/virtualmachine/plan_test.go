@@ -0,0 +1,202 @@
+package virtualmachine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testState returns a state of the resource's schema holding values, with
+// every other attribute null. A nil map returns a null state, as before the
+// VM is created.
+func testState(t *testing.T, values map[string]attr.Value) tfsdk.State {
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	(&Resource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	state := tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}
+	for name, value := range values {
+		diags := state.SetAttribute(ctx, path.Root(name), value)
+		require.False(t, diags.HasError(), "setting %s: %v", name, diags)
+	}
+	return state
+}
+
+// testPlan is testState for plans.
+func testPlan(t *testing.T, values map[string]attr.Value) tfsdk.Plan {
+	state := testState(t, values)
+	return tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+}
+
+// testConfig is testState for configurations.
+func testConfig(t *testing.T, values map[string]attr.Value) tfsdk.Config {
+	state := testState(t, values)
+	return tfsdk.Config{Schema: state.Schema, Raw: state.Raw}
+}
+
+// attributePaths returns the attribute each of diags is about.
+func attributePaths(diags diag.Diagnostics) []string {
+	var paths []string
+	for _, d := range diags {
+		if withPath, ok := d.(diag.DiagnosticWithPath); ok {
+			paths = append(paths, withPath.Path().String())
+		}
+	}
+	return paths
+}
+
+func TestExplainReplacement(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     map[string]attr.Value
+		plan      map[string]attr.Value
+		migrating bool
+		want      []string
+	}{
+		{
+			name:  "provisioning-only attribute changes",
+			state: map[string]attr.Value{"template": types.StringValue("Windows2019_Standard_30GB")},
+			plan:  map[string]attr.Value{"template": types.StringValue("Windows2022_Standard_30GB")},
+			want:  []string{"template"},
+		},
+		{
+			name:  "provisioning-only attribute set on an imported VM",
+			state: map[string]attr.Value{"name": types.StringValue("test-vm")},
+			plan:  map[string]attr.Value{"template": types.StringValue("Windows2022_Standard_30GB")},
+		},
+		{
+			name:      "default network changes with a migration",
+			state:     map[string]attr.Value{"hosting_location_default_network": types.StringValue("CHC-CUST-SDC-WAN")},
+			plan:      map[string]attr.Value{"hosting_location_default_network": types.StringValue("AKL-CUST-SDC-WAN")},
+			migrating: true,
+		},
+		{
+			name:  "default network changes without a migration",
+			state: map[string]attr.Value{"hosting_location_default_network": types.StringValue("CHC-CUST-SDC-WAN")},
+			plan:  map[string]attr.Value{"hosting_location_default_network": types.StringValue("CHC-CUST-SDC-LAN")},
+			want:  []string{"hosting_location_default_network"},
+		},
+		{
+			name:  "rename without replace_on_rename",
+			state: map[string]attr.Value{"name": types.StringValue("test-vm")},
+			plan:  map[string]attr.Value{"name": types.StringValue("test-vm-renamed")},
+		},
+		{
+			name:  "rename with replace_on_rename",
+			state: map[string]attr.Value{"name": types.StringValue("test-vm")},
+			plan: map[string]attr.Value{
+				"name":              types.StringValue("test-vm-renamed"),
+				"replace_on_rename": types.BoolValue(true),
+			},
+			want: []string{"name"},
+		},
+		{
+			name:  "guest OS changes with replace_on_guest_os_change",
+			state: map[string]attr.Value{"guest_os_id": newGuestOSValue("windows2019srv_64Guest")},
+			plan: map[string]attr.Value{
+				"guest_os_id":                newGuestOSValue("windows2019srvNext_64Guest"),
+				"replace_on_guest_os_change": types.BoolValue(true),
+			},
+			want: []string{"guest_os_id"},
+		},
+		{
+			name:  "guest OS named another way with replace_on_guest_os_change",
+			state: map[string]attr.Value{"guest_os_id": newGuestOSValue("windows2019srv_64Guest")},
+			plan: map[string]attr.Value{
+				"guest_os_id":                newGuestOSValue("Microsoft Windows Server 2019 (64-bit)"),
+				"replace_on_guest_os_change": types.BoolValue(true),
+			},
+		},
+		{
+			name:  "migration with replace_on_migrate",
+			state: map[string]attr.Value{"hosting_location_id": types.StringValue("vcchcres")},
+			plan: map[string]attr.Value{
+				"hosting_location_id": types.StringValue("vcaklres"),
+				"replace_on_migrate":  types.BoolValue(true),
+			},
+			migrating: true,
+			want:      []string{"hosting_location_id"},
+		},
+		{
+			name:  "cores change with replace_on_resize",
+			state: map[string]attr.Value{"cores": types.Int64Value(2), "sockets": types.Int64Value(1), "cores_per_socket": types.Int64Value(2)},
+			plan: map[string]attr.Value{
+				"cores":             types.Int64Value(4),
+				"sockets":           types.Int64Value(1),
+				"cores_per_socket":  types.Int64Value(4),
+				"replace_on_resize": types.BoolValue(true),
+			},
+			want: []string{"cores", "cores_per_socket"},
+		},
+		{
+			name:  "topology still unknown with replace_on_resize",
+			state: map[string]attr.Value{"cores": types.Int64Value(2), "sockets": types.Int64Value(1)},
+			plan: map[string]attr.Value{
+				"cores":             types.Int64Value(2),
+				"sockets":           types.Int64Unknown(),
+				"replace_on_resize": types.BoolValue(true),
+			},
+		},
+		{
+			name:  "memory resized with replace_on_resize",
+			state: map[string]attr.Value{"memory_size": types.Int64Value(2)},
+			plan: map[string]attr.Value{
+				"memory_size":       types.Int64Value(4),
+				"replace_on_resize": types.BoolValue(true),
+			},
+			want: []string{"memory_size"},
+		},
+		{
+			name:  "memory moved to memory_mb and resized with replace_on_resize",
+			state: map[string]attr.Value{"memory_size": types.Int64Value(2)},
+			plan: map[string]attr.Value{
+				"memory_mb":         types.Int64Value(4096),
+				"replace_on_resize": types.BoolValue(true),
+			},
+			want: []string{"memory_mb"},
+		},
+		{
+			name:  "same memory in the other unit with replace_on_resize",
+			state: map[string]attr.Value{"memory_size": types.Int64Value(2)},
+			plan: map[string]attr.Value{
+				"memory_mb":         types.Int64Value(2048),
+				"replace_on_resize": types.BoolValue(true),
+			},
+		},
+		{
+			name:  "memory resized without replace_on_resize",
+			state: map[string]attr.Value{"memory_size": types.Int64Value(2)},
+			plan:  map[string]attr.Value{"memory_size": types.Int64Value(4)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			req := resource.ModifyPlanRequest{
+				Plan:  testPlan(t, tt.plan),
+				State: testState(t, tt.state),
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+
+			// When
+			explainReplacement(context.Background(), req, resp, tt.migrating)
+
+			// Then
+			assert.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			assert.ElementsMatch(t, tt.want, attributePaths(resp.Diagnostics.Warnings()))
+		})
+	}
+}
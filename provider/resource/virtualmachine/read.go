@@ -1,32 +1,50 @@
 package virtualmachine
 
 import (
+	"context"
 	"terraform-provider-vbridge/api"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 )
 
-func Read(d *schema.ResourceData, meta interface{}) error {
-	apiClient := meta.(*api.Client)
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vmID := state.Id.ValueString()
+	vm, err := r.client.GetVMDetailedByID(ctx, vmID)
+	if err != nil {
+		// Drop VMs that have been deleted outside Terraform from state rather
+		// than failing the refresh.
+		exists, existsErr := r.client.VMExists(ctx, vmID, int(state.ClientId.ValueInt64()))
+		if existsErr == nil && !exists {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading virtual machine", err.Error())
+		return
+	}
 
-	vmID := d.Id()
-	vm, err := apiClient.GetVMDetailedByID(vmID)
+	state.setFromVM(vm)
+
+	snapshots, err := r.listSnapshots(ctx, vm)
 	if err != nil {
-		return err
+		resp.Diagnostics.AddError("Error reading virtual machine snapshots", err.Error())
+		return
 	}
 
-	d.Set("client_id", vm.ClientId)
-	d.Set("name", vm.Name)
-	d.Set("guest_os_id", vm.GuestOsId)
-	d.Set("cores", vm.Specification.Cores)
-	d.Set("memory_size", vm.Specification.MemoryGb)
-	d.Set("mo_ref", vm.Specification.MoRef)
-	d.Set("operating_system_disk_guid", vm.Specification.VirtualDisks[0].MoRef)
-	d.Set("operating_system_disk_capacity", int(vm.Specification.VirtualDisks[0].Capacity))
-	d.Set("operating_system_disk_storage_profile", vm.Specification.VirtualDisks[0].Tier)
-	d.Set("backup_type", vm.Specification.BackupType)
-	d.Set("hosting_location_id", vm.Specification.HostingLocationId)
-	d.Set("vm_id", vm.Id.String())
-
-	return nil
+	state.setSnapshots(snapshots)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+// listSnapshots lists a VM's snapshots, skipping the request when the
+// detailed response says it has none.
+func (r *Resource) listSnapshots(ctx context.Context, vm api.VirtualMachine) ([]api.Snapshot, error) {
+	if !vm.HasSnapshot {
+		return nil, nil
+	}
+	return r.client.ListSnapshots(ctx, vm.Id.String())
 }
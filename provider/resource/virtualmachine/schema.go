@@ -1,104 +1,459 @@
 package virtualmachine
 
 import (
+	"context"
 	"fmt"
+	"terraform-provider-vbridge/api"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func Schema() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		"client_id": {
-			Type:     schema.TypeInt,
-			Required: true,
-		},
-		"name": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
-		"template": {
-			Type:     schema.TypeString,
-			Optional: true,
-		},
-		"guest_os_id": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
-		"cores": {
-			Type:     schema.TypeInt,
-			Required: true,
-		},
-		"memory_size": {
-			Type:     schema.TypeInt,
-			Required: true,
-		},
-		"operating_system_disk_guid": {
-			Type:     schema.TypeString,
-			Computed: true,
-		},
-		"operating_system_disk_capacity": {
-			Type:     schema.TypeInt,
-			Optional: true,
-			Computed: true,
-			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
-				if v, ok := val.(int); ok && v <= 0 {
-					errs = append(errs, fmt.Errorf("%q must be a positive integer, got: %d", key, v))
-				}
-				return
+func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-		},
-		"operating_system_disk_storage_profile": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
-		"additional_disks": {
-			Type:     schema.TypeList,
-			Optional: true,
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"capacity": {
-						Type:     schema.TypeInt,
-						Required: true,
+			"client_id": schema.Int64Attribute{
+				Required: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(replaceOnRename,
+						"Replaces the VM when `replace_on_rename` is true.",
+						"Replaces the VM when `replace_on_rename` is true."),
+				},
+			},
+			"replace_on_rename": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Replace the VM instead of renaming it in place when `name` changes.",
+			},
+			"template": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					requiresReplaceIfKnown(),
+				},
+			},
+			"guest_os_id": schema.StringAttribute{
+				Required:    true,
+				CustomType:  guestOSType{},
+				Description: "vSphere guest OS identifier, e.g. `windows2019srv_64Guest`, or the name the portal shows for it, e.g. `Microsoft Windows Server 2019 (64-bit)`. Changing it powers the VM off while the guest OS is changed.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(replaceOnGuestOSChange,
+						"Replaces the VM when `replace_on_guest_os_change` is true.",
+						"Replaces the VM when `replace_on_guest_os_change` is true."),
+				},
+			},
+			"replace_on_guest_os_change": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Replace the VM instead of changing its guest OS in place when `guest_os_id` changes.",
+			},
+			"guest_os_full_name": schema.StringAttribute{
+				Computed:    true,
+				Description: "Name of the guest OS as reported by the API.",
+			},
+			"cores": schema.Int64Attribute{
+				Required:    true,
+				Description: "Total number of vCPUs, `sockets` times `cores_per_socket`.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(replaceOnResize,
+						"Replaces the VM when `replace_on_resize` is true.",
+						"Replaces the VM when `replace_on_resize` is true."),
+				},
+			},
+			"sockets": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Number of CPU sockets. Set this or `cores_per_socket` to pin the topology, e.g. for per-socket licensing. Changing the topology powers the VM off.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(replaceOnResize,
+						"Replaces the VM when `replace_on_resize` is true.",
+						"Replaces the VM when `replace_on_resize` is true."),
+				},
+			},
+			"cores_per_socket": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Number of cores in each CPU socket. When neither this nor `sockets` is set, the VM keeps its cores per socket if `cores` still divides evenly, or gets a single socket.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(replaceOnResize,
+						"Replaces the VM when `replace_on_resize` is true.",
+						"Replaces the VM when `replace_on_resize` is true."),
+				},
+			},
+			"replace_on_resize": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Replace the VM instead of changing its CPUs or memory in place when `cores`, `sockets`, `cores_per_socket` or the memory size change.",
+			},
+			"memory_size": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Memory in GB. Exactly one of `memory_size` or `memory_mb` is required.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(replaceOnMemoryResize,
+						"Replaces the VM when `replace_on_resize` is true.",
+						"Replaces the VM when `replace_on_resize` is true."),
+				},
+			},
+			"memory_mb": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Memory in MB, for sizes that aren't a whole number of GB. Must be within the hosting location's limits and a multiple of its increment. Removing memory powers the VM off.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(replaceOnMemoryResize,
+						"Replaces the VM when `replace_on_resize` is true.",
+						"Replaces the VM when `replace_on_resize` is true."),
+				},
+			},
+			"operating_system_disk_guid": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"operating_system_disk_capacity": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Size of the operating system disk in GB, when `template` isn't set. Increasing it extends the disk in place; it can't be shrunk.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"operating_system_disk_storage_profile": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"iso_file": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					requiresReplaceIfKnown(),
+				},
+			},
+			"quote_item": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplaceIf(replaceIfKnownMap,
+						"Replaces the VM if the value was known before.", "Replaces the VM if the value was known before."),
+				},
+			},
+			"hosting_location_id": schema.StringAttribute{
+				Required:    true,
+				Description: "Hosting location of the VM, e.g. `vcchcres`. Changing it migrates the VM to the new location.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(replaceOnMigrate,
+						"Replaces the VM when `replace_on_migrate` is true.",
+						"Replaces the VM when `replace_on_migrate` is true."),
+				},
+			},
+			"replace_on_migrate": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Replace the VM in the new hosting location instead of migrating it when `hosting_location_id` changes.",
+			},
+			"hosting_location_name": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					requiresReplaceUnlessMigrating(),
+				},
+			},
+			"hosting_location_default_network": schema.StringAttribute{
+				Required:    true,
+				Description: "Network the VM is connected to when provisioned, and that its NICs move to when it is migrated unless `network_mapping` says otherwise.",
+				PlanModifiers: []planmodifier.String{
+					requiresReplaceUnlessMigrating(),
+				},
+			},
+			"network_mapping": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Networks to reconnect the VM's NICs to when `hosting_location_id` changes, keyed by the network they are on now. Only used when migrating.",
+			},
+			"backup_type": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vm_id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"mo_ref": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"virtual_disks": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Every disk attached to the VM, starting with the operating system disk.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"mo_ref": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"capacity": schema.Int64Attribute{
+							Computed: true,
+						},
+						"storage_profile": schema.StringAttribute{
+							Computed: true,
+						},
 					},
-					"storage_profile": {
-						Type:     schema.TypeString,
-						Required: true,
+				},
+			},
+			"network_devices": schema.ListNestedAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"mo_ref": schema.StringAttribute{
+							Computed: true,
+						},
+						"network_name": schema.StringAttribute{
+							Computed: true,
+						},
+						"network_id": schema.StringAttribute{
+							Computed: true,
+						},
+						"mac_address": schema.StringAttribute{
+							Computed: true,
+						},
+						"connected": schema.BoolAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"license": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the guest OS license the VM consumes, e.g. `Windows Server 2022`. Licenses assigned outside Terraform are left alone unless this is set.",
+			},
+			"license_id": schema.Int64Attribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"license_key": schema.StringAttribute{
+				Computed:  true,
+				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"annotation": schema.StringAttribute{
+				Optional:    true,
+				Description: "Notes shown on the VM in the portal. Notes written in the portal are left alone unless this is set.",
+			},
+			"tags": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Tags for the VM, stored in its annotation after a `[tags]` line.",
+			},
+			"tags_all": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The VM's tags including the provider's `default_tags`.",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"snapshots": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Snapshots of the VM, including those taken outside Terraform.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"description": schema.StringAttribute{
+							Computed: true,
+						},
+						"created_on": schema.StringAttribute{
+							Computed: true,
+						},
 					},
 				},
 			},
 		},
-		"iso_file": {
-			Type:     schema.TypeString,
-			Optional: true,
-		},
-		"quote_item": {
-			Type:     schema.TypeMap,
-			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
-		"hosting_location_id": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
-		"hosting_location_name": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
-		"hosting_location_default_network": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
-		"backup_type": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
-		"vm_id": {
-			Type:     schema.TypeString,
-			Computed: true,
-		},
-		"mo_ref": {
-			Type:     schema.TypeString,
-			Computed: true,
+		Blocks: map[string]schema.Block{
+			"additional_disks": schema.ListNestedBlock{
+				Description: "Disks added when the VM is provisioned. Use `vbridge_virtual_machine_additionaldisk` for disks managed afterwards.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplaceIf(replaceIfKnownList,
+						"Replaces the VM if the value was known before.", "Replaces the VM if the value was known before."),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"capacity": schema.Int64Attribute{
+							Required: true,
+						},
+						"storage_profile": schema.StringAttribute{
+							Required: true,
+						},
+					},
+				},
+			},
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
+
+// ValidateConfig checks the operating system disk, tag and CPU topology
+// settings. Unknown values are skipped; they are validated again once known.
+func (r *Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config resourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Template.IsUnknown() || config.OperatingSystemDiskCapacity.IsUnknown() {
+		return
+	}
+
+	templateSet := !config.Template.IsNull()
+	capacitySet := !config.OperatingSystemDiskCapacity.IsNull()
+
+	if templateSet && capacitySet {
+		resp.Diagnostics.AddAttributeError(path.Root("operating_system_disk_capacity"), "Conflicting configuration",
+			"`operating_system_disk_capacity` should not be set when `template` is specified")
+	} else if !templateSet && !capacitySet {
+		resp.Diagnostics.AddAttributeError(path.Root("operating_system_disk_capacity"), "Missing configuration",
+			"`operating_system_disk_capacity` is required when `template` is not specified")
+	}
+
+	if !config.Tags.IsUnknown() {
+		tags := make(map[string]types.String)
+		resp.Diagnostics.Append(config.Tags.ElementsAs(ctx, &tags, false)...)
+		for key, value := range tags {
+			if err := api.ValidateTag(key, value.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("tags").AtMapKey(key), "Invalid tag", err.Error())
+			}
+		}
+	}
+
+	if capacitySet && config.OperatingSystemDiskCapacity.ValueInt64() <= 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("operating_system_disk_capacity"), "Invalid configuration",
+			fmt.Sprintf("`operating_system_disk_capacity` must be a positive integer, got: %d", config.OperatingSystemDiskCapacity.ValueInt64()))
+	}
+
+	validateTopology(config, resp)
+	validateMemory(config, resp)
+}
+
+// replaceOnRename replaces the VM on a name change if replace_on_rename is
+// planned true, for when something outside vBridge depends on the old name.
+func replaceOnRename(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	var replace types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("replace_on_rename"), &replace)...)
+	resp.RequiresReplace = replace.ValueBool()
+}
+
+// replaceOnGuestOSChange replaces the VM on a guest OS change if
+// replace_on_guest_os_change is planned true. Naming the same guest OS
+// another way isn't a change, and neither is setting it on an imported VM.
+func replaceOnGuestOSChange(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	var replace types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("replace_on_guest_os_change"), &replace)...)
+	resp.RequiresReplace = replace.ValueBool() && !req.StateValue.IsNull() && !req.PlanValue.IsUnknown() &&
+		!newGuestOSValue(req.PlanValue.ValueString()).Same(newGuestOSValue(req.StateValue.ValueString()))
+}
+
+// replaceOnMigrate replaces the VM on a hosting location change if
+// replace_on_migrate is planned true, provisioning it in the new location
+// instead of migrating it there.
+func replaceOnMigrate(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	var replace types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("replace_on_migrate"), &replace)...)
+	resp.RequiresReplace = replace.ValueBool()
+}
+
+// replaceOnResize replaces the VM on a CPU change if replace_on_resize is
+// planned true. Sockets and cores per socket that aren't configured are
+// unknown until planTopology works them out, and only change along with
+// cores.
+func replaceOnResize(ctx context.Context, req planmodifier.Int64Request, resp *int64planmodifier.RequiresReplaceIfFuncResponse) {
+	var replace types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("replace_on_resize"), &replace)...)
+	resp.RequiresReplace = replace.ValueBool() && !req.PlanValue.IsUnknown()
+}
+
+// requiresReplaceIfKnown replaces the VM when an attribute that is only sent
+// when provisioning changes. Imported VMs don't have these in state, so
+// setting them afterwards doesn't replace the VM.
+func requiresReplaceIfKnown() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+		resp.RequiresReplace = !req.StateValue.IsNull()
+	}, "Replaces the VM if the value was known before.", "Replaces the VM if the value was known before.")
+}
+
+// replaceIfKnownMap is requiresReplaceIfKnown for maps.
+func replaceIfKnownMap(ctx context.Context, req planmodifier.MapRequest, resp *mapplanmodifier.RequiresReplaceIfFuncResponse) {
+	resp.RequiresReplace = !req.StateValue.IsNull()
+}
+
+// replaceIfKnownList is requiresReplaceIfKnown for lists.
+func replaceIfKnownList(ctx context.Context, req planmodifier.ListRequest, resp *listplanmodifier.RequiresReplaceIfFuncResponse) {
+	resp.RequiresReplace = !req.StateValue.IsNull()
+}
+
+// requiresReplaceUnlessMigrating is requiresReplaceIfKnown for the hosting
+// location's other attributes, which change without replacing the VM when
+// hosting_location_id changes too.
+func requiresReplaceUnlessMigrating() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+		var planned, current types.String
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("hosting_location_id"), &planned)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("hosting_location_id"), &current)...)
+		resp.RequiresReplace = !req.StateValue.IsNull() && planned.Equal(current)
+	}, "Replaces the VM if the value was known before, unless it is being migrated.", "Replaces the VM if the value was known before, unless it is being migrated.")
+}
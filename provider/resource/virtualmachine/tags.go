@@ -0,0 +1,123 @@
+package virtualmachine
+
+import (
+	"context"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// planTagsAll works out tags_all from the provider's default_tags and the
+// VM's tags, so changes to either show up in the plan. If tags aren't
+// managed, tags_all keeps reflecting whatever tags the VM has.
+func (r *Resource) planTagsAll(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var tags, stateTags types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("tags"), &tags)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("tags"), &stateTags)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if tags.IsUnknown() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tags_all"), types.MapUnknown(types.StringType))...)
+		return
+	}
+
+	if !req.State.Raw.IsNull() && !r.managesTags(tags, stateTags) {
+		var tagsAll types.Map
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("tags_all"), &tagsAll)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tags_all"), tagsAll)...)
+		return
+	}
+
+	tagsAll := make(map[string]string)
+	for key, value := range r.defaultTags {
+		tagsAll[key] = value
+	}
+
+	configured := make(map[string]string)
+	resp.Diagnostics.Append(tags.ElementsAs(ctx, &configured, false)...)
+	for key, value := range configured {
+		tagsAll[key] = value
+	}
+
+	tagsAllValue, diags := types.MapValueFrom(ctx, types.StringType, tagsAll)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tags_all"), tagsAllValue)...)
+}
+
+// setTagsFromVM decodes the annotation. Notes and tags are only tracked if
+// they are managed here; tags_all always reflects the VM.
+func (m *resourceModel) setTagsFromVM(vm api.VirtualMachine) {
+	notes, tagsAll := api.DecodeAnnotation(vm.Annotation)
+
+	if !m.Annotation.IsNull() {
+		m.Annotation = types.StringValue(notes)
+	}
+
+	if !m.Tags.IsNull() && !m.Tags.IsUnknown() {
+		tags := make(map[string]attr.Value)
+		for key := range m.Tags.Elements() {
+			if value, ok := tagsAll[key]; ok {
+				tags[key] = types.StringValue(value)
+			}
+		}
+		m.Tags = types.MapValueMust(types.StringType, tags)
+	}
+
+	values := make(map[string]attr.Value, len(tagsAll))
+	for key, value := range tagsAll {
+		values[key] = types.StringValue(value)
+	}
+	m.TagsAll = types.MapValueMust(types.StringType, values)
+}
+
+// managesTags reports whether the VM's tags are managed here: they are
+// configured, were configured before, or default_tags add some. Otherwise
+// any tags section in the annotation was written elsewhere and is kept.
+func (r *Resource) managesTags(tags, stateTags types.Map) bool {
+	return !tags.IsNull() || !stateTags.IsNull() || len(r.defaultTags) > 0
+}
+
+// writeAnnotation stores the planned notes and tags_all in the VM's
+// annotation. When annotation isn't set the VM's current notes are kept, as
+// are its current tags when they aren't managed here.
+func (r *Resource) writeAnnotation(ctx context.Context, vmID string, plan, state resourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	manageTags := r.managesTags(plan.Tags, state.Tags)
+	notes := plan.Annotation.ValueString()
+	tags := make(map[string]string)
+	if plan.Annotation.IsNull() || !manageTags {
+		vm, err := r.client.GetVMDetailedByID(ctx, vmID)
+		if err != nil {
+			diags.AddError("Error reading virtual machine", err.Error())
+			return diags
+		}
+
+		currentNotes, currentTags := api.DecodeAnnotation(vm.Annotation)
+		if plan.Annotation.IsNull() {
+			notes = currentNotes
+		}
+		if !manageTags {
+			tags = currentTags
+		}
+	}
+
+	if manageTags {
+		diags.Append(plan.TagsAll.ElementsAs(ctx, &tags, false)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	if err := r.client.SetAnnotation(ctx, vmID, api.EncodeAnnotation(notes, tags)); err != nil {
+		diags.AddError("Error updating annotation", err.Error())
+	}
+	return diags
+}
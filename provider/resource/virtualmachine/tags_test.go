@@ -0,0 +1,176 @@
+package virtualmachine
+
+import (
+	"context"
+	"terraform-provider-vbridge/api"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTags returns a tags map holding tags.
+func testTags(tags map[string]string) types.Map {
+	values := make(map[string]attr.Value, len(tags))
+	for key, value := range tags {
+		values[key] = types.StringValue(value)
+	}
+	return types.MapValueMust(types.StringType, values)
+}
+
+func TestSetTagsFromVM(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotation     types.String
+		tags           types.Map
+		vmAnnotation   string
+		wantAnnotation types.String
+		wantTags       types.Map
+		wantTagsAll    types.Map
+	}{
+		{
+			name:           "notes and tags managed",
+			annotation:     types.StringValue("Web server"),
+			tags:           testTags(map[string]string{"owner": "ops"}),
+			vmAnnotation:   "Intranet web server\n\n[tags]\nowner=dev\nteam=platform",
+			wantAnnotation: types.StringValue("Intranet web server"),
+			wantTags:       testTags(map[string]string{"owner": "dev"}),
+			wantTagsAll:    testTags(map[string]string{"owner": "dev", "team": "platform"}),
+		},
+		{
+			name:           "configured tag removed outside Terraform",
+			annotation:     types.StringNull(),
+			tags:           testTags(map[string]string{"owner": "ops", "team": "web"}),
+			vmAnnotation:   "[tags]\nowner=ops",
+			wantAnnotation: types.StringNull(),
+			wantTags:       testTags(map[string]string{"owner": "ops"}),
+			wantTagsAll:    testTags(map[string]string{"owner": "ops"}),
+		},
+		{
+			name:           "nothing managed",
+			annotation:     types.StringNull(),
+			tags:           types.MapNull(types.StringType),
+			vmAnnotation:   "Intranet web server\n\n[tags]\nowner=ops",
+			wantAnnotation: types.StringNull(),
+			wantTags:       types.MapNull(types.StringType),
+			wantTagsAll:    testTags(map[string]string{"owner": "ops"}),
+		},
+		{
+			name:           "notes without tags",
+			annotation:     types.StringValue("Web server"),
+			tags:           types.MapNull(types.StringType),
+			vmAnnotation:   "Restored from backup 2024-08-16\nby the service desk",
+			wantAnnotation: types.StringValue("Restored from backup 2024-08-16\nby the service desk"),
+			wantTags:       types.MapNull(types.StringType),
+			wantTagsAll:    testTags(map[string]string{}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			model := resourceModel{Annotation: tt.annotation, Tags: tt.tags}
+
+			// When
+			model.setTagsFromVM(api.VirtualMachine{Annotation: tt.vmAnnotation})
+
+			// Then
+			assert.Equal(t, tt.wantAnnotation, model.Annotation)
+			assert.Equal(t, tt.wantTags, model.Tags)
+			assert.Equal(t, tt.wantTagsAll, model.TagsAll)
+		})
+	}
+}
+
+func TestManagesTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        types.Map
+		stateTags   types.Map
+		defaultTags map[string]string
+		want        bool
+	}{
+		{"tags configured", testTags(map[string]string{"owner": "ops"}), types.MapNull(types.StringType), nil, true},
+		{"tags configured before", types.MapNull(types.StringType), testTags(map[string]string{"owner": "ops"}), nil, true},
+		{"default tags", types.MapNull(types.StringType), types.MapNull(types.StringType), map[string]string{"team": "platform"}, true},
+		{"no tags", types.MapNull(types.StringType), types.MapNull(types.StringType), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			r := &Resource{defaultTags: tt.defaultTags}
+
+			// When
+			got := r.managesTags(tt.tags, tt.stateTags)
+
+			// Then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPlanTagsAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       map[string]attr.Value
+		tags        types.Map
+		defaultTags map[string]string
+		want        types.Map
+	}{
+		{
+			name:        "tags merged over default tags",
+			tags:        testTags(map[string]string{"owner": "ops", "team": "web"}),
+			defaultTags: map[string]string{"team": "platform", "cost-centre": "1234"},
+			want:        testTags(map[string]string{"owner": "ops", "team": "web", "cost-centre": "1234"}),
+		},
+		{
+			name:        "default tags only",
+			tags:        types.MapNull(types.StringType),
+			defaultTags: map[string]string{"team": "platform"},
+			want:        testTags(map[string]string{"team": "platform"}),
+		},
+		{
+			name:  "unmanaged tags kept",
+			state: map[string]attr.Value{"tags_all": testTags(map[string]string{"owner": "ops"})},
+			tags:  types.MapNull(types.StringType),
+			want:  testTags(map[string]string{"owner": "ops"}),
+		},
+		{
+			name:  "tags removed",
+			state: map[string]attr.Value{"tags": testTags(map[string]string{"owner": "ops"}), "tags_all": testTags(map[string]string{"owner": "ops"})},
+			tags:  types.MapNull(types.StringType),
+			want:  testTags(map[string]string{}),
+		},
+		{
+			name: "tags unknown",
+			tags: types.MapUnknown(types.StringType),
+			want: types.MapUnknown(types.StringType),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			ctx := context.Background()
+			r := &Resource{defaultTags: tt.defaultTags}
+			req := resource.ModifyPlanRequest{
+				Plan:  testPlan(t, map[string]attr.Value{"tags": tt.tags, "tags_all": types.MapUnknown(types.StringType)}),
+				State: testState(t, tt.state),
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+
+			// When
+			r.planTagsAll(ctx, req, resp)
+
+			// Then
+			var tagsAll types.Map
+			resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("tags_all"), &tagsAll)...)
+			assert.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			assert.Equal(t, tt.want, tagsAll)
+		})
+	}
+}
@@ -1,9 +1,194 @@
 package virtualmachine
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"context"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func Update(d *schema.ResourceData, meta interface{}) error {
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	vmID := plan.Id.ValueString()
+
+	if !plan.HostingLocationId.Equal(state.HostingLocationId) {
+		resp.Diagnostics.Append(r.migrate(ctx, vmID, plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if !plan.Name.Equal(state.Name) {
+		if err := r.client.RenameVM(ctx, vmID, int(plan.ClientId.ValueInt64()), plan.Name.ValueString()); err != nil {
+			resp.Diagnostics.AddError("Error renaming virtual machine", err.Error())
+			return
+		}
+	}
+
+	// Without the current guest OS, e.g. after an import, there is nothing
+	// to compare against, so the VM isn't power cycled.
+	if !plan.GuestOsId.Same(state.GuestOsId) && !state.GuestOsId.IsNull() {
+		if err := r.changeGuestOS(ctx, vmID, api.NormalizeGuestOSId(plan.GuestOsId.ValueString())); err != nil {
+			resp.Diagnostics.AddError("Error changing guest OS", err.Error())
+			return
+		}
+	}
+
+	if !plan.Cores.Equal(state.Cores) || !plan.CoresPerSocket.Equal(state.CoresPerSocket) {
+		if err := r.updateCPU(ctx, vmID, plan, state); err != nil {
+			resp.Diagnostics.AddError("Error updating CPU", err.Error())
+			return
+		}
+	}
+
+	if !plan.OperatingSystemDiskCapacity.IsUnknown() && !plan.OperatingSystemDiskCapacity.Equal(state.OperatingSystemDiskCapacity) {
+		err := r.client.ExtendVMDisk(ctx, vmID, state.OperatingSystemDiskGuid.ValueString(), int(plan.OperatingSystemDiskCapacity.ValueInt64()))
+		if err != nil {
+			resp.Diagnostics.AddError("Error extending operating system disk", err.Error())
+			return
+		}
+	}
+
+	if plan.memoryMb() != state.memoryMb() {
+		if err := r.updateMemory(ctx, vmID, plan, state); err != nil {
+			resp.Diagnostics.AddError("Error updating memory", err.Error())
+			return
+		}
+	}
+
+	if !plan.License.Equal(state.License) {
+		if err := r.updateLicense(ctx, vmID, plan.License); err != nil {
+			resp.Diagnostics.AddError("Error updating license", err.Error())
+			return
+		}
+	}
+
+	if !plan.Annotation.Equal(state.Annotation) || !plan.TagsAll.Equal(state.TagsAll) {
+		resp.Diagnostics.Append(r.writeAnnotation(ctx, vmID, plan, state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	vm, err := r.client.GetVMDetailedByID(ctx, vmID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading virtual machine", err.Error())
+		return
+	}
+
+	plan.setComputedFromVM(vm)
+
+	snapshots, err := r.listSnapshots(ctx, vm)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading virtual machine snapshots", err.Error())
+		return
+	}
+
+	plan.setSnapshots(snapshots)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// migrate moves the VM to the planned hosting location. NICs on networks
+// missing from network_mapping move to the new default network.
+func (r *Resource) migrate(ctx context.Context, vmID string, plan resourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	networkMapping := make(map[string]string)
+	if !plan.NetworkMapping.IsNull() {
+		diags.Append(plan.NetworkMapping.ElementsAs(ctx, &networkMapping, false)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	vm, err := r.client.GetVMDetailedByID(ctx, vmID)
+	if err != nil {
+		diags.AddError("Error reading virtual machine", err.Error())
+		return diags
+	}
+
+	for _, nic := range vm.Specification.NetworkDevices {
+		if _, ok := networkMapping[nic.NetworkName]; !ok {
+			networkMapping[nic.NetworkName] = plan.HostingLocationDefaultNetwork.ValueString()
+		}
+	}
+
+	if _, err := r.client.MigrateVM(ctx, vmID, plan.HostingLocationId.ValueString(), networkMapping); err != nil {
+		diags.AddError("Error migrating virtual machine", err.Error())
+	}
+	return diags
+}
+
+// changeGuestOS powers the VM off to change its guest OS.
+func (r *Resource) changeGuestOS(ctx context.Context, vmID string, guestOsId string) error {
+	return r.whilePoweredOff(ctx, vmID, func() error {
+		return r.client.ChangeGuestOS(ctx, vmID, guestOsId)
+	})
+}
+
+// whilePoweredOff powers the VM off to run change, then powers it back on
+// if it was running.
+func (r *Resource) whilePoweredOff(ctx context.Context, vmID string, change func() error) error {
+	vm, err := r.client.GetVMDetailedByID(ctx, vmID)
+	if err != nil {
+		return err
+	}
+
+	poweredOn := vm.Specification.PowerState != api.PowerStateOff
+	if poweredOn {
+		if err := r.client.PowerOffVM(ctx, vmID); err != nil {
+			return err
+		}
+		if err := r.client.WaitForPowerState(ctx, vmID, api.PowerStateOff); err != nil {
+			return err
+		}
+	}
+
+	if err := change(); err != nil {
+		return err
+	}
+
+	if poweredOn {
+		if err := r.client.PowerOnVM(ctx, vmID); err != nil {
+			return err
+		}
+		return r.client.WaitForPowerState(ctx, vmID, api.PowerStateOn)
+	}
+	return nil
+}
+
+// updateLicense assigns the named license, which replaces the current one,
+// or removes the current license if name is null.
+func (r *Resource) updateLicense(ctx context.Context, vmID string, name types.String) error {
+	if !name.IsNull() {
+		_, err := r.client.AssignLicense(ctx, vmID, name.ValueString())
+		return err
+	}
+
+	vm, err := r.client.GetVMDetailedByID(ctx, vmID)
+	if err != nil {
+		return err
+	}
+
+	if license := vm.AssignedLicense(); license != nil {
+		return r.client.RemoveLicense(ctx, vmID, license.LicenseId)
+	}
 	return nil
 }
@@ -0,0 +1,108 @@
+package virtualmachine_test
+
+import (
+	"regexp"
+	"terraform-provider-vbridge/api"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+// Test for pinning the CPU topology and keeping it when cores change
+func TestAccVirtualMachine_cpuTopology(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-cpu", "cores": 4}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores", "4"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "sockets", "1"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores_per_socket", "4"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-cpu", "cores": 4, "sockets": 4}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					testAccCheckVirtualMachinePowerState(mockAPI.URL, "vbridge_virtual_machine.vm", api.PowerStateOn),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores", "4"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "sockets", "4"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores_per_socket", "1"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-cpu", "cores": 6}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores", "6"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "sockets", "6"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores_per_socket", "1"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-cpu", "cores": 6, "cores_per_socket": 4}),
+
+				// THEN
+				ExpectError: regexp.MustCompile(`must\s+divide`),
+			},
+		},
+	})
+}
+
+// Test for replacing a virtual machine when its CPUs change, if asked to
+func TestAccVirtualMachine_replaceOnResize(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-cpu", "cores": 4, "replace_on_resize": true}),
+				Check:  testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-cpu", "cores": 8, "replace_on_resize": true}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineExists(mockAPI.URL, "vbridge_virtual_machine.vm"),
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, true),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "cores", "8"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,142 @@
+package virtualmachine_test
+
+import (
+	"terraform-provider-vbridge/api"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+// Test for changing the guest OS in place and replacing the VM when its
+// default network changes
+func TestAccVirtualMachine_guestOSAndReplacement(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-placement"}),
+				Check:  testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-placement", "guest_os_id": "windows2019srvNext_64Guest"}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					testAccCheckVirtualMachinePowerState(mockAPI.URL, "vbridge_virtual_machine.vm", api.PowerStateOn),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "guest_os_id", "windows2019srvNext_64Guest"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "guest_os_full_name", "Microsoft Windows Server 2022 (64-bit)"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-placement", "guest_os_id": "windows2019srvNext_64Guest", "hosting_location_default_network": "CHC-CUST-SDC-LAN"}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, true),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "network_devices.0.network_name", "CHC-CUST-SDC-LAN"),
+				),
+			},
+		},
+	})
+}
+
+// Test for configuring the guest OS by the name the API reports
+func TestAccVirtualMachine_guestOSName(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-placement", "guest_os_id": "Microsoft Windows Server 2019 (64-bit)"}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineExists(mockAPI.URL, "vbridge_virtual_machine.vm"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "guest_os_id", "Microsoft Windows Server 2019 (64-bit)"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "guest_os_full_name", "Microsoft Windows Server 2019 (64-bit)"),
+				),
+			},
+			{
+				// WHEN
+				RefreshState: true,
+
+				// THEN
+				RefreshPlanChecks: resource.RefreshPlanChecks{
+					PostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+// Test for replacing a virtual machine when its guest OS changes, but not
+// when the same guest OS is named another way
+func TestAccVirtualMachine_replaceOnGuestOSChange(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-guest-os-replace", "guest_os_id": "windows2019srv_64Guest", "replace_on_guest_os_change": true}),
+				Check:  testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-guest-os-replace", "guest_os_id": "Microsoft Windows Server 2019 (64-bit)", "replace_on_guest_os_change": true}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-guest-os-replace", "guest_os_id": "windows2019srvNext_64Guest", "replace_on_guest_os_change": true}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, true),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "guest_os_full_name", "Microsoft Windows Server 2022 (64-bit)"),
+				),
+			},
+		},
+	})
+}
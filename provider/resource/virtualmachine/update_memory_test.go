@@ -0,0 +1,143 @@
+package virtualmachine_test
+
+import (
+	"regexp"
+	"terraform-provider-vbridge/api"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+// Test for sizing memory in MB or GB, keeping the configured unit and
+// checking the hosting location's limits
+func TestAccVirtualMachine_memory(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": nil, "memory_mb": 1536}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "memory_mb", "1536"),
+					resource.TestCheckNoResourceAttr("vbridge_virtual_machine.vm", "memory_size"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": 2}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "memory_size", "2"),
+					resource.TestCheckNoResourceAttr("vbridge_virtual_machine.vm", "memory_mb"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": nil, "memory_mb": 1024}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					testAccCheckVirtualMachinePowerState(mockAPI.URL, "vbridge_virtual_machine.vm", api.PowerStateOn),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "memory_mb", "1024"),
+					resource.TestCheckNoResourceAttr("vbridge_virtual_machine.vm", "memory_size"),
+				),
+			},
+			{
+				// WHEN
+				RefreshState: true,
+
+				// THEN
+				RefreshPlanChecks: resource.RefreshPlanChecks{
+					PostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": nil, "memory_mb": 1000}),
+
+				// THEN
+				ExpectError: regexp.MustCompile(`multiple\s+of\s+256\s+MB`),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": 2048}),
+
+				// THEN
+				ExpectError: regexp.MustCompile(`at\s+most\s+1048576\s+MB`),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": 2, "memory_mb": 2048}),
+
+				// THEN
+				ExpectError: regexp.MustCompile(`should\s+not\s+be\s+set`),
+			},
+		},
+	})
+}
+
+// Test for replacing a virtual machine when its memory changes, if asked to,
+// but not when the same size is given in the other unit
+func TestAccVirtualMachine_replaceOnMemoryResize(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": 2, "replace_on_resize": true}),
+				Check:  testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": nil, "memory_mb": 2048, "replace_on_resize": true}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-memory", "memory_size": nil, "memory_mb": 4096, "replace_on_resize": true}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, true),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "memory_mb", "4096"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,95 @@
+package virtualmachine_test
+
+import (
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+// Test for migrating a virtual machine between hosting locations
+func TestAccVirtualMachine_migrate(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-migrate"}),
+				Check:  testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-migrate", "hosting_location_id": "vcaklres", "hosting_location_name": "Auckland", "hosting_location_default_network": "AKL-CUST-SDC-WAN"}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "hosting_location_id", "vcaklres"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "network_devices.0.network_name", "AKL-CUST-SDC-WAN"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-migrate", "network_mapping": hcl(`{ "AKL-CUST-SDC-WAN" = "CHC-CUST-SDC-LAN" }`)}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "hosting_location_id", "vcchcres"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "network_devices.0.network_name", "CHC-CUST-SDC-LAN"),
+				),
+			},
+		},
+	})
+}
+
+// Test for replacing a virtual machine in the new hosting location instead
+// of migrating it
+func TestAccVirtualMachine_replaceOnMigrate(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-migrate", "replace_on_migrate": true}),
+				Check:  testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-migrate", "hosting_location_id": "vcaklres", "hosting_location_name": "Auckland", "hosting_location_default_network": "AKL-CUST-SDC-WAN", "replace_on_migrate": true}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineExists(mockAPI.URL, "vbridge_virtual_machine.vm"),
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, true),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "hosting_location_id", "vcaklres"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "network_devices.0.network_name", "AKL-CUST-SDC-WAN"),
+				),
+			},
+		},
+	})
+}
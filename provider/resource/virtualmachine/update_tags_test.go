@@ -0,0 +1,155 @@
+package virtualmachine_test
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+// Test configuration
+func testAccProviderConfig_defaultTags(apiURL string) string {
+	return fmt.Sprintf(`
+provider "vbridge" {
+  api_url    = %q
+  api_key    = %q
+  user_email = %q
+
+  default_tags = {
+    team = "platform"
+  }
+}
+`, apiURL, acctest.APIKey, acctest.UserEmail)
+}
+
+// Test for managing the annotation and tags, merged with default_tags
+func TestAccVirtualMachine_tags(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccProviderConfig_defaultTags(mockAPI.URL) + testAccVirtualMachineResource(testAccVirtualMachineArgs{"name": "test-vm-tags", "annotation": "Intranet web server", "tags": hcl(`{ owner = "ops" }`)}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineAnnotation(mockAPI.URL, "vbridge_virtual_machine.vm", "Intranet web server\n\n[tags]\nowner=ops\nteam=platform"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "annotation", "Intranet web server"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "tags.%", "1"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "tags_all.%", "2"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "tags_all.team", "platform"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccProviderConfig_defaultTags(mockAPI.URL) + testAccVirtualMachineResource(testAccVirtualMachineArgs{"name": "test-vm-tags", "annotation": "Intranet web server", "tags": hcl(`{ owner = "dev", team = "web" }`)}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineAnnotation(mockAPI.URL, "vbridge_virtual_machine.vm", "Intranet web server\n\n[tags]\nowner=dev\nteam=web"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "tags_all.team", "web"),
+				),
+			},
+			{
+				// WHEN
+				// Notes are left alone once annotation is no longer set.
+				Config: testAccProviderConfig_defaultTags(mockAPI.URL) + testAccVirtualMachineResource(testAccVirtualMachineArgs{"name": "test-vm-tags"}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineAnnotation(mockAPI.URL, "vbridge_virtual_machine.vm", "Intranet web server\n\n[tags]\nteam=platform"),
+					resource.TestCheckNoResourceAttr("vbridge_virtual_machine.vm", "annotation"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "tags_all.%", "1"),
+				),
+			},
+		},
+	})
+}
+
+// Test that tags written outside Terraform are kept when only the notes are
+// managed
+func TestAccVirtualMachine_unmanagedTags(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-unmanaged-tags", "annotation": "Intranet web server"}),
+			},
+			{
+				// WHEN
+				PreConfig: func() {
+					client, err := acctest.Client(mockAPI.URL)
+					if err != nil {
+						t.Fatal(err)
+					}
+					vmID, err := client.GetVMByName(context.Background(), "test-vm-unmanaged-tags", acctest.ClientId)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := client.SetAnnotation(context.Background(), vmID, "Intranet web server\n\n[tags]\nowner=ops"); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-unmanaged-tags", "annotation": "Public web server"}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("vbridge_virtual_machine.vm", tfjsonpath.New("tags_all"),
+							knownvalue.MapExact(map[string]knownvalue.Check{"owner": knownvalue.StringExact("ops")})),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineAnnotation(mockAPI.URL, "vbridge_virtual_machine.vm", "Public web server\n\n[tags]\nowner=ops"),
+					resource.TestCheckNoResourceAttr("vbridge_virtual_machine.vm", "tags.%"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "tags_all.owner", "ops"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckVirtualMachineAnnotation checks the annotation stored in the
+// API.
+func testAccCheckVirtualMachineAnnotation(apiURL, resourceName, annotation string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		vm, err := client.GetVMDetailedByID(context.Background(), rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if vm.Annotation != annotation {
+			return fmt.Errorf("expected annotation %q, got %q", annotation, vm.Annotation)
+		}
+
+		return nil
+	}
+}
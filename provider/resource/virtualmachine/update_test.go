@@ -0,0 +1,275 @@
+package virtualmachine_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+// Test for updating a virtual machine resource in place
+func TestAccVirtualMachine_update(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, nil),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, nil, `  timeouts {
+    delete = "30m"
+  }`),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+						// Computed attributes the change doesn't affect stay known
+						plancheck.ExpectKnownValue("vbridge_virtual_machine.vm", tfjsonpath.New("virtual_disks"), knownvalue.NotNull()),
+						plancheck.ExpectKnownValue("vbridge_virtual_machine.vm", tfjsonpath.New("network_devices"), knownvalue.NotNull()),
+						plancheck.ExpectKnownValue("vbridge_virtual_machine.vm", tfjsonpath.New("snapshots"), knownvalue.NotNull()),
+						plancheck.ExpectKnownValue("vbridge_virtual_machine.vm", tfjsonpath.New("tags_all"), knownvalue.NotNull()),
+						plancheck.ExpectKnownValue("vbridge_virtual_machine.vm", tfjsonpath.New("license_id"), knownvalue.Null()),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineExists(mockAPI.URL, "vbridge_virtual_machine.vm"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "timeouts.delete", "30m"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "operating_system_disk_capacity", "30"),
+				),
+			},
+		},
+	})
+}
+
+// Test for assigning, changing and removing a license
+func TestAccVirtualMachine_license(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-license", "license": "Windows Server 2019"}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "license", "Windows Server 2019"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine.vm", "license_id"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine.vm", "license_key"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-license", "license": "Windows Server 2022"}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "license", "Windows Server 2022"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine.vm", "license_key"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-license"}),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckNoResourceAttr("vbridge_virtual_machine.vm", "license"),
+					resource.TestCheckNoResourceAttr("vbridge_virtual_machine.vm", "license_id"),
+					resource.TestCheckNoResourceAttr("vbridge_virtual_machine.vm", "license_key"),
+				),
+			},
+		},
+	})
+}
+
+// Test for renaming a virtual machine in place, and replacing it when asked to
+func TestAccVirtualMachine_rename(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-rename", "replace_on_rename": false}),
+				Check:  testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-renamed", "replace_on_rename": false}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineExists(mockAPI.URL, "vbridge_virtual_machine.vm"),
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "name", "test-vm-renamed"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-replaced", "replace_on_rename": true}),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineExists(mockAPI.URL, "vbridge_virtual_machine.vm"),
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, true),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "name", "test-vm-replaced"),
+				),
+			},
+		},
+	})
+}
+
+// testAccAdditionalDisksBlock adds a disk when the test VM is provisioned.
+const testAccAdditionalDisksBlock = `  additional_disks {
+    capacity        = 10
+    storage_profile = "vStorageT2"
+  }`
+
+// Test for provisioning additional disks, extending the operating system
+// disk in place and replacing the VM when its backup type changes
+func TestAccVirtualMachine_disks(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+	var vmID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVirtualMachineDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-disks", "template": nil, "operating_system_disk_capacity": 40, "backup_type": "vBackupDisk"}, testAccAdditionalDisksBlock),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "virtual_disks.#", "2"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "virtual_disks.1.capacity", "10"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "virtual_disks.1.storage_profile", "vStorageT2"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-disks", "template": nil, "operating_system_disk_capacity": 50, "backup_type": "vBackupDisk"}, testAccAdditionalDisksBlock),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, false),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "operating_system_disk_capacity", "50"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "virtual_disks.0.capacity", "50"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-disks", "template": nil, "operating_system_disk_capacity": 40, "backup_type": "vBackupDisk"}, testAccAdditionalDisksBlock),
+
+				// THEN
+				ExpectError: regexp.MustCompile("can't be shrunk"),
+			},
+			{
+				// WHEN
+				Config: testAccVirtualMachineConfig(mockAPI.URL, testAccVirtualMachineArgs{"name": "test-vm-disks", "template": nil, "operating_system_disk_capacity": 50, "backup_type": "vBackupNone"}, testAccAdditionalDisksBlock),
+
+				// THEN
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("vbridge_virtual_machine.vm", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineID("vbridge_virtual_machine.vm", &vmID, true),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "backup_type", "vBackupNone"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "virtual_disks.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckVirtualMachineID records the ID of the VM in state the first
+// time it is called, then checks later IDs match, or differ if replaced.
+func testAccCheckVirtualMachineID(resourceName string, vmID *string, replaced bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+
+		switch {
+		case *vmID == "":
+			*vmID = rs.Primary.ID
+		case replaced && rs.Primary.ID == *vmID:
+			return fmt.Errorf("expected a new VM, still %s", *vmID)
+		case !replaced && rs.Primary.ID != *vmID:
+			return fmt.Errorf("expected VM %s, got %s", *vmID, rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckVirtualMachinePowerState checks the power state reported by
+// the API.
+func testAccCheckVirtualMachinePowerState(apiURL, resourceName, powerState string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		vm, err := client.GetVMDetailedByID(context.Background(), rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if vm.Specification.PowerState != powerState {
+			return fmt.Errorf("expected power state %s, got %s", powerState, vm.Specification.PowerState)
+		}
+
+		return nil
+	}
+}
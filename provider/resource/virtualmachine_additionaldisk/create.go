@@ -1,27 +1,51 @@
 package additionaldisk
 
 import (
+	"context"
 	"terraform-provider-vbridge/api"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func Create(d *schema.ResourceData, meta interface{}) error {
-	apiClient := meta.(*api.Client)
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 
 	disk := api.VirtualDisk{
-		Capacity:       d.Get("capacity").(int),
-		StorageProfile: d.Get("storage_profile").(string),
+		Capacity:       int(plan.Capacity.ValueInt64()),
+		StorageProfile: plan.StorageProfile.ValueString(),
 	}
 
-	vmID := d.Get("vm_id").(string)
+	vmID := plan.VmId.ValueString()
 
-	diskID, err := apiClient.CreateAdditionalDiskWithComparison(vmID, disk)
+	diskID, err := r.client.CreateAdditionalDiskWithComparison(ctx, vmID, disk)
 	if err != nil {
-		return err
+		resp.Diagnostics.AddError("Error creating additional disk", err.Error())
+		return
 	}
 
-	d.SetId(diskID)
+	plan.Id = types.StringValue(diskID)
+
+	vmDisk, err := r.client.GetVMDisk(ctx, vmID, diskID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading additional disk", err.Error())
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
 
-	return Read(d, meta)
+	plan.setFromDisk(vmDisk)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
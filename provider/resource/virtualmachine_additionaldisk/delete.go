@@ -1,25 +1,42 @@
 package additionaldisk
 
 import (
-	"terraform-provider-vbridge/api"
-
+	"context"
+	"errors"
 	"fmt"
+	"terraform-provider-vbridge/api"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 )
 
-func Delete(d *schema.ResourceData, meta interface{}) error {
-	apiClient := meta.(*api.Client)
-
-	diskID := d.Id()
-	vmID := d.Get("vm_id")
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	err := apiClient.DeleteVMDisk(vmID.(string), diskID)
-	if err != nil {
-		return fmt.Errorf("error deleting VM: %w", err)
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	d.SetId("")
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
 
-	return nil
+	diskID := state.Id.ValueString()
+	vmID := state.VmId.ValueString()
+
+	// Nothing to wait for if the disk has already been detached.
+	_, err := r.client.GetVMDisk(ctx, vmID, diskID)
+	if errors.Is(err, api.ErrNotFound) {
+		return
+	}
+
+	err = r.client.DeleteVMDisk(ctx, vmID, diskID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error deleting additional disk", fmt.Sprintf("error deleting disk %s from VM %s: %s", diskID, vmID, err))
+		return
+	}
 }
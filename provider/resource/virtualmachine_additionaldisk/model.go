@@ -0,0 +1,21 @@
+package additionaldisk
+
+import (
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type resourceModel struct {
+	Id             types.String   `tfsdk:"id"`
+	Capacity       types.Int64    `tfsdk:"capacity"`
+	StorageProfile types.String   `tfsdk:"storage_profile"`
+	VmId           types.String   `tfsdk:"vm_id"`
+	Timeouts       timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (m *resourceModel) setFromDisk(disk *api.VirtualDisk) {
+	m.Capacity = types.Int64Value(int64(disk.Capacity))
+	m.StorageProfile = types.StringValue(disk.Tier)
+}
@@ -0,0 +1,31 @@
+package additionaldisk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ModifyPlan rejects shrinking the disk, which can only be extended.
+// Otherwise ExtendVMDisk would report success straight away, since the disk
+// is already at least the planned size.
+func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var capacity, currentCapacity types.Int64
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("capacity"), &capacity)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("capacity"), &currentCapacity)...)
+	if resp.Diagnostics.HasError() || capacity.IsUnknown() || capacity.IsNull() || currentCapacity.IsNull() {
+		return
+	}
+
+	if capacity.ValueInt64() < currentCapacity.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(path.Root("capacity"), "Invalid disk size",
+			fmt.Sprintf("The disk can't be shrunk from %d GB to %d GB.", currentCapacity.ValueInt64(), capacity.ValueInt64()))
+	}
+}
@@ -1,24 +1,29 @@
 package additionaldisk
 
 import (
+	"context"
+	"errors"
 	"terraform-provider-vbridge/api"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 )
 
-func Read(d *schema.ResourceData, meta interface{}) error {
-	apiClient := meta.(*api.Client)
-
-	vmID := d.Get("vm_id").(string)
-	diskID := d.Id()
-
-	vmDisk, err := apiClient.GetVMDisk(vmID, diskID)
-	if err != nil {
-		return err
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	d.Set("capacity", vmDisk.Capacity)
-	d.Set("storage_profile", vmDisk.Tier)
+	vmDisk, err := r.client.GetVMDisk(ctx, state.VmId.ValueString(), state.Id.ValueString())
+	if errors.Is(err, api.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Error reading additional disk", err.Error())
+		return
+	}
 
-	return nil
+	state.setFromDisk(vmDisk)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 }
@@ -1,16 +1,64 @@
 package additionaldisk
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-vbridge/api"
+	"terraform-provider-vbridge/internal/providerdata"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 )
 
-func Resource() *schema.Resource {
-	return &schema.Resource{
-		Create: Create,
-		Read:   Read,
-		Update: Update,
-		Delete: Delete,
+const (
+	defaultCreateTimeout = 10 * time.Minute
+	defaultUpdateTimeout = 10 * time.Minute
+	defaultDeleteTimeout = 10 * time.Minute
+)
 
-		Schema: Schema(),
+var (
+	_ resource.Resource                = &Resource{}
+	_ resource.ResourceWithConfigure   = &Resource{}
+	_ resource.ResourceWithImportState = &Resource{}
+	_ resource.ResourceWithModifyPlan  = &Resource{}
+)
+
+type Resource struct {
+	client *api.Client
+}
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_virtual_machine_additionaldisk"
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerdata.Data)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *providerdata.Data, got: %T", req.ProviderData))
+		return
+	}
+
+	r.client = data.Client
+}
+
+// ImportState accepts IDs of the form <vm_id>/<disk_moref>.
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	vmID, diskID, ok := strings.Cut(req.ID, "/")
+	if !ok || vmID == "" || diskID == "" {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Expected an ID of the form <vm_id>/<disk_moref>, got: %q", req.ID))
+		return
 	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vm_id"), vmID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), diskID)...)
 }
@@ -0,0 +1,143 @@
+package additionaldisk_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// Test configuration
+func testAccAdditionalDiskConfig(apiURL string, capacity int) string {
+	return acctest.ProviderConfig(apiURL) + fmt.Sprintf(`
+resource "vbridge_virtual_machine" "vm" {
+  client_id                             = %d
+  name                                  = "test-vm-disk"
+  template                              = "Windows2022_Standard_30GB"
+  guest_os_id                           = "windows2019srv_64Guest"
+  cores                                 = 2
+  memory_size                           = 6
+  operating_system_disk_storage_profile = "vStorageT1"
+  hosting_location_id                   = "vcchcres"
+  hosting_location_name                 = "Christchurch"
+  hosting_location_default_network      = "CHC-CUST-SDC-WAN"
+  backup_type                           = "vBackupDisk"
+}
+
+resource "vbridge_virtual_machine_additionaldisk" "disk" {
+  vm_id           = vbridge_virtual_machine.vm.vm_id
+  capacity        = %d
+  storage_profile = "vStorageT1"
+}
+`, acctest.ClientId, capacity)
+}
+
+// Test for adding, extending, importing and removing an additional disk
+func TestAccAdditionalDisk_basic(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckAdditionalDiskDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccAdditionalDiskConfig(mockAPI.URL, 20),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAdditionalDiskCapacity(mockAPI.URL, "vbridge_virtual_machine_additionaldisk.disk", 20),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_additionaldisk.disk", "capacity", "20"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_additionaldisk.disk", "storage_profile", "vStorageT1"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine_additionaldisk.disk", "id"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccAdditionalDiskConfig(mockAPI.URL, 30),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAdditionalDiskCapacity(mockAPI.URL, "vbridge_virtual_machine_additionaldisk.disk", 30),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_additionaldisk.disk", "capacity", "30"),
+				),
+			},
+			{
+				// WHEN
+				Config: testAccAdditionalDiskConfig(mockAPI.URL, 20),
+
+				// THEN
+				ExpectError: regexp.MustCompile("can't be shrunk"),
+			},
+			{
+				// WHEN
+				ResourceName:      "vbridge_virtual_machine_additionaldisk.disk",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs, ok := s.RootModule().Resources["vbridge_virtual_machine_additionaldisk.disk"]
+					if !ok {
+						return "", fmt.Errorf("resource not found: vbridge_virtual_machine_additionaldisk.disk")
+					}
+					return rs.Primary.Attributes["vm_id"] + "/" + rs.Primary.ID, nil
+				},
+
+				// THEN
+				ImportStateVerifyIgnore: []string{"timeouts"},
+			},
+		},
+	})
+}
+
+// testAccCheckAdditionalDiskCapacity checks the disk in state exists in the
+// API with the expected capacity.
+func testAccCheckAdditionalDiskCapacity(apiURL, resourceName string, capacity int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		disk, err := client.GetVMDisk(context.Background(), rs.Primary.Attributes["vm_id"], rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if disk.Capacity != capacity {
+			return fmt.Errorf("expected disk %s to have capacity %d, got %d", rs.Primary.ID, capacity, disk.Capacity)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckAdditionalDiskDestroy checks every disk in state has been removed.
+func testAccCheckAdditionalDiskDestroy(apiURL string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "vbridge_virtual_machine_additionaldisk" {
+				continue
+			}
+
+			if _, err := client.GetVMDisk(context.Background(), rs.Primary.Attributes["vm_id"], rs.Primary.ID); err == nil {
+				return fmt.Errorf("disk %s still exists on VM %s", rs.Primary.ID, rs.Primary.Attributes["vm_id"])
+			}
+		}
+
+		return nil
+	}
+}
@@ -1,26 +1,35 @@
 package additionaldisk
 
 import (
-	"terraform-provider-vbridge/api"
+	"context"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 )
 
-func Update(d *schema.ResourceData, meta interface{}) error {
-	apiClient := meta.(*api.Client)
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	diskID := d.Id()
-	vmID := d.Get("vm_id")
-	if d.HasChange("capacity") {
-		_, newSize := d.GetChange("capacity")
-		newDiskSize := newSize.(int)
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
 
-		err := apiClient.ExtendVMDisk(vmID.(string), diskID, newDiskSize)
+	if !plan.Capacity.Equal(state.Capacity) {
+		err := r.client.ExtendVMDisk(ctx, plan.VmId.ValueString(), plan.Id.ValueString(), int(plan.Capacity.ValueInt64()))
 		if err != nil {
-			return err
+			resp.Diagnostics.AddError("Error extending additional disk", err.Error())
+			return
 		}
-
-		d.Set("capacity", newDiskSize)
 	}
-	return nil
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
@@ -0,0 +1,51 @@
+package restore
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	vmID := plan.VmId.ValueString()
+	restorePointID := plan.RestorePointId.ValueString()
+
+	task, err := r.client.RestoreVM(ctx, vmID, restorePointID, restoreTypes[plan.RestoreType.ValueString()])
+	if err != nil {
+		resp.Diagnostics.AddError("Error restoring virtual machine", err.Error())
+		return
+	}
+
+	plan.Id = types.StringValue(vmID + "/" + restorePointID)
+	plan.TaskId = types.StringValue(task.Id)
+	plan.Status = types.StringValue(task.Status)
+	plan.setInstantRecoveryDisks(nil)
+
+	if plan.RestoreType.ValueString() == restoreTypeInstantRecovery {
+		disks, err := r.client.WaitForInstantRecoveryDisks(ctx, vmID, restorePointID)
+		if err != nil {
+			resp.Diagnostics.AddError("Error reading instant recovery disks", err.Error())
+			resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+			return
+		}
+		plan.setInstantRecoveryDisks(disks)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
@@ -0,0 +1,49 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.RestoreType.ValueString() != restoreTypeInstantRecovery {
+		resp.Diagnostics.AddWarning("Full restore not undone",
+			fmt.Sprintf("VM %s stays as it was restored from %s; destroying the restore only removes it from state.",
+				state.VmId.ValueString(), state.RestorePointId.ValueString()))
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	vmID := state.VmId.ValueString()
+	restorePointID := state.RestorePointId.ValueString()
+
+	// Nothing to do if the disks have already been unmounted.
+	_, err := r.client.GetInstantRecoveryDisks(ctx, vmID, restorePointID)
+	if errors.Is(err, api.ErrNotFound) {
+		return
+	}
+
+	err = r.client.UnmountInstantRecovery(ctx, vmID, restorePointID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error unmounting instant recovery", fmt.Sprintf("error unmounting %s from VM %s: %s", restorePointID, vmID, err))
+		return
+	}
+}
@@ -0,0 +1,38 @@
+package restore
+
+import (
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type resourceModel struct {
+	Id                   types.String   `tfsdk:"id"`
+	VmId                 types.String   `tfsdk:"vm_id"`
+	RestorePointId       types.String   `tfsdk:"restore_point_id"`
+	RestoreType          types.String   `tfsdk:"restore_type"`
+	TaskId               types.String   `tfsdk:"task_id"`
+	Status               types.String   `tfsdk:"status"`
+	InstantRecoveryDisks types.List     `tfsdk:"instant_recovery_disks"`
+	Timeouts             timeouts.Value `tfsdk:"timeouts"`
+}
+
+var instantRecoveryDiskAttrTypes = map[string]attr.Type{
+	"name":     types.StringType,
+	"mo_ref":   types.StringType,
+	"capacity": types.Int64Type,
+}
+
+func (m *resourceModel) setInstantRecoveryDisks(disks []api.InstantRecoveryDisk) {
+	values := make([]attr.Value, 0, len(disks))
+	for _, disk := range disks {
+		values = append(values, types.ObjectValueMust(instantRecoveryDiskAttrTypes, map[string]attr.Value{
+			"name":     types.StringValue(disk.Name),
+			"mo_ref":   types.StringValue(disk.MoRef),
+			"capacity": types.Int64Value(int64(disk.Capacity)),
+		}))
+	}
+	m.InstantRecoveryDisks = types.ListValueMust(types.ObjectType{AttrTypes: instantRecoveryDiskAttrTypes}, values)
+}
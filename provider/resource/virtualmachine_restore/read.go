@@ -0,0 +1,35 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// Read only has something to check for instant recoveries. A full restore
+// is a finished job and stays in state as a record of it.
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.RestoreType.ValueString() != restoreTypeInstantRecovery {
+		return
+	}
+
+	disks, err := r.client.GetInstantRecoveryDisks(ctx, state.VmId.ValueString(), state.RestorePointId.ValueString())
+	if errors.Is(err, api.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Error reading instant recovery disks", err.Error())
+		return
+	}
+
+	state.setInstantRecoveryDisks(disks)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
@@ -0,0 +1,78 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-vbridge/api"
+	"terraform-provider-vbridge/internal/providerdata"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+const (
+	defaultCreateTimeout = 4 * time.Hour
+	defaultDeleteTimeout = 30 * time.Minute
+)
+
+const (
+	restoreTypeFull            = "full"
+	restoreTypeInstantRecovery = "instant_recovery"
+)
+
+// restoreTypes maps restore_type to the API's restore types.
+var restoreTypes = map[string]string{
+	restoreTypeFull:            api.RestoreTypeFull,
+	restoreTypeInstantRecovery: api.RestoreTypeInstantRecovery,
+}
+
+var (
+	_ resource.Resource                   = &Resource{}
+	_ resource.ResourceWithConfigure      = &Resource{}
+	_ resource.ResourceWithValidateConfig = &Resource{}
+)
+
+type Resource struct {
+	client *api.Client
+}
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_virtual_machine_restore"
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerdata.Data)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *providerdata.Data, got: %T", req.ProviderData))
+		return
+	}
+
+	r.client = data.Client
+}
+
+// ValidateConfig checks restore_type is one the API supports.
+func (r *Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config resourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.RestoreType.IsNull() || config.RestoreType.IsUnknown() {
+		return
+	}
+
+	if _, ok := restoreTypes[config.RestoreType.ValueString()]; !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("restore_type"), "Invalid configuration",
+			fmt.Sprintf("`restore_type` must be %q or %q, got: %q", restoreTypeFull, restoreTypeInstantRecovery, config.RestoreType.ValueString()))
+	}
+}
@@ -0,0 +1,115 @@
+package restore_test
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// Test configuration
+func testAccRestoreConfig(apiURL string, restoreType string) string {
+	return acctest.ProviderConfig(apiURL) + fmt.Sprintf(`
+resource "vbridge_virtual_machine" "vm" {
+  client_id                             = %d
+  name                                  = "test-vm-restore"
+  template                              = "Windows2022_Standard_30GB"
+  guest_os_id                           = "windows2019srv_64Guest"
+  cores                                 = 2
+  memory_size                           = 6
+  operating_system_disk_storage_profile = "vStorageT1"
+  hosting_location_id                   = "vcchcres"
+  hosting_location_name                 = "Christchurch"
+  hosting_location_default_network      = "CHC-CUST-SDC-WAN"
+  backup_type                           = "vBackupDisk"
+}
+
+data "vbridge_restore_point" "vm" {
+  vm_id = vbridge_virtual_machine.vm.vm_id
+}
+
+resource "vbridge_virtual_machine_restore" "restore" {
+  vm_id            = vbridge_virtual_machine.vm.vm_id
+  restore_point_id = data.vbridge_restore_point.vm.latest_id
+  restore_type     = %q
+
+  lifecycle {
+    ignore_changes = [restore_point_id]
+  }
+}
+`, acctest.ClientId, restoreType)
+}
+
+// Test for mounting and unmounting an instant recovery
+func TestAccRestore_instantRecovery(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckInstantRecoveryUnmounted(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccRestoreConfig(mockAPI.URL, "instant_recovery"),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_restore.restore", "status", "Completed"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine_restore.restore", "task_id"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_restore.restore", "instant_recovery_disks.#", "1"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_restore.restore", "instant_recovery_disks.0.capacity", "30"),
+				),
+			},
+		},
+	})
+}
+
+// Test for a full restore, which stays in state until destroyed
+func TestAccRestore_full(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccRestoreConfig(mockAPI.URL, "full"),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_restore.restore", "status", "Completed"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_restore.restore", "instant_recovery_disks.#", "0"),
+					resource.TestCheckResourceAttrPair("vbridge_virtual_machine_restore.restore", "restore_point_id", "data.vbridge_restore_point.vm", "latest_id"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckInstantRecoveryUnmounted checks no VM in the API still has
+// instant recovery disks mounted.
+func testAccCheckInstantRecoveryUnmounted(apiURL string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "vbridge_virtual_machine_restore" {
+				continue
+			}
+
+			if _, err := client.GetInstantRecoveryDisks(context.Background(), rs.Primary.Attributes["vm_id"], rs.Primary.Attributes["restore_point_id"]); err == nil {
+				return fmt.Errorf("restore point %s is still mounted on VM %s", rs.Primary.Attributes["restore_point_id"], rs.Primary.Attributes["vm_id"])
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,39 @@
+package snapshot
+
+import (
+	"context"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	snapshot, err := r.client.CreateSnapshot(ctx, plan.VmId.ValueString(), api.Snapshot{
+		Name:          plan.Name.ValueString(),
+		Description:   plan.Description.ValueString(),
+		IncludeMemory: plan.IncludeMemory.ValueBool(),
+		Quiesce:       plan.Quiesce.ValueBool(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating snapshot", err.Error())
+		return
+	}
+
+	plan.setFromSnapshot(snapshot)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
@@ -0,0 +1,50 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	snapshotID := state.Id.ValueString()
+	vmID := state.VmId.ValueString()
+
+	// Nothing to do if the snapshot has already been removed.
+	_, err := r.client.GetSnapshot(ctx, vmID, snapshotID)
+	if errors.Is(err, api.ErrNotFound) {
+		return
+	}
+
+	if state.RevertOnDestroy.ValueBool() {
+		err = r.client.RevertSnapshot(ctx, vmID, snapshotID)
+		if err != nil {
+			resp.Diagnostics.AddError("Error reverting to snapshot", fmt.Sprintf("error reverting VM %s to snapshot %s: %s", vmID, snapshotID, err))
+			return
+		}
+	}
+
+	err = r.client.DeleteSnapshot(ctx, vmID, snapshotID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error deleting snapshot", fmt.Sprintf("error deleting snapshot %s from VM %s: %s", snapshotID, vmID, err))
+		return
+	}
+}
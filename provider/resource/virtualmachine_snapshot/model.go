@@ -0,0 +1,31 @@
+package snapshot
+
+import (
+	"terraform-provider-vbridge/api"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type resourceModel struct {
+	Id              types.String   `tfsdk:"id"`
+	VmId            types.String   `tfsdk:"vm_id"`
+	Name            types.String   `tfsdk:"name"`
+	Description     types.String   `tfsdk:"description"`
+	IncludeMemory   types.Bool     `tfsdk:"include_memory"`
+	Quiesce         types.Bool     `tfsdk:"quiesce"`
+	RevertOnDestroy types.Bool     `tfsdk:"revert_on_destroy"`
+	WarnAfter       types.String   `tfsdk:"warn_after"`
+	CreatedOn       types.String   `tfsdk:"created_on"`
+	Timeouts        timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (m *resourceModel) setFromSnapshot(snapshot *api.Snapshot) {
+	m.Id = types.StringValue(snapshot.Id)
+	m.Name = types.StringValue(snapshot.Name)
+	m.Description = types.StringValue(snapshot.Description)
+	m.IncludeMemory = types.BoolValue(snapshot.IncludeMemory)
+	m.Quiesce = types.BoolValue(snapshot.Quiesce)
+	m.CreatedOn = types.StringValue(snapshot.CreatedOn.Format(time.RFC3339))
+}
@@ -0,0 +1,29 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"terraform-provider-vbridge/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	snapshot, err := r.client.GetSnapshot(ctx, state.VmId.ValueString(), state.Id.ValueString())
+	if errors.Is(err, api.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Error reading snapshot", err.Error())
+		return
+	}
+
+	state.setFromSnapshot(snapshot)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
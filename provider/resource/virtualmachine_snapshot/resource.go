@@ -0,0 +1,121 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-vbridge/api"
+	"terraform-provider-vbridge/internal/providerdata"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+const (
+	defaultCreateTimeout = 30 * time.Minute
+	defaultDeleteTimeout = 30 * time.Minute
+)
+
+var (
+	_ resource.Resource                   = &Resource{}
+	_ resource.ResourceWithConfigure      = &Resource{}
+	_ resource.ResourceWithImportState    = &Resource{}
+	_ resource.ResourceWithValidateConfig = &Resource{}
+	_ resource.ResourceWithModifyPlan     = &Resource{}
+)
+
+type Resource struct {
+	client *api.Client
+}
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_virtual_machine_snapshot"
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerdata.Data)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *providerdata.Data, got: %T", req.ProviderData))
+		return
+	}
+
+	r.client = data.Client
+}
+
+// ImportState accepts IDs of the form <vm_id>/<snapshot_id>.
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	vmID, snapshotID, ok := strings.Cut(req.ID, "/")
+	if !ok || vmID == "" || snapshotID == "" {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Expected an ID of the form <vm_id>/<snapshot_id>, got: %q", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vm_id"), vmID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), snapshotID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("revert_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("warn_after"), defaultWarnAfter)...)
+}
+
+// ValidateConfig checks warn_after is a duration.
+func (r *Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config resourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.WarnAfter.IsNull() || config.WarnAfter.IsUnknown() {
+		return
+	}
+
+	warnAfter, err := time.ParseDuration(config.WarnAfter.ValueString())
+	if err != nil || warnAfter < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("warn_after"), "Invalid configuration",
+			fmt.Sprintf("`warn_after` must be a non-negative duration such as \"72h\", got: %q", config.WarnAfter.ValueString()))
+	}
+}
+
+// ModifyPlan warns about snapshots that have been kept longer than
+// warn_after. Long-lived snapshots grow with every write to the VM and slow
+// down its disks.
+func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.WarnAfter.IsUnknown() || state.CreatedOn.IsNull() {
+		return
+	}
+
+	warnAfter, err := time.ParseDuration(plan.WarnAfter.ValueString())
+	if err != nil || warnAfter == 0 {
+		return
+	}
+
+	createdOn, err := time.Parse(time.RFC3339, state.CreatedOn.ValueString())
+	if err != nil {
+		return
+	}
+
+	if age := time.Since(createdOn); age > warnAfter {
+		resp.Diagnostics.AddWarning("Snapshot is older than warn_after",
+			fmt.Sprintf("Snapshot %q of VM %s was taken %s ago. Snapshots slow down the VM's disks the longer they are kept; consider removing it.",
+				state.Name.ValueString(), state.VmId.ValueString(), age.Round(time.Minute)))
+	}
+}
@@ -0,0 +1,134 @@
+package snapshot_test
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-vbridge/internal/acctest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// Test configuration
+func testAccSnapshotConfig(apiURL string) string {
+	return acctest.ProviderConfig(apiURL) + fmt.Sprintf(`
+resource "vbridge_virtual_machine" "vm" {
+  client_id                             = %d
+  name                                  = "test-vm-snapshot"
+  template                              = "Windows2022_Standard_30GB"
+  guest_os_id                           = "windows2019srv_64Guest"
+  cores                                 = 2
+  memory_size                           = 6
+  operating_system_disk_storage_profile = "vStorageT1"
+  hosting_location_id                   = "vcchcres"
+  hosting_location_name                 = "Christchurch"
+  hosting_location_default_network      = "CHC-CUST-SDC-WAN"
+  backup_type                           = "vBackupDisk"
+}
+
+resource "vbridge_virtual_machine_snapshot" "snapshot" {
+  vm_id          = vbridge_virtual_machine.vm.vm_id
+  name           = "pre-patch"
+  description    = "Before patching"
+  include_memory = true
+}
+`, acctest.ClientId)
+}
+
+// Test for taking, importing and removing a snapshot
+func TestAccSnapshot_basic(t *testing.T) {
+	// GIVEN
+	mockAPI := acctest.NewMockAPI(t)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckSnapshotDestroy(mockAPI.URL),
+		Steps: []resource.TestStep{
+			{
+				// WHEN
+				Config: testAccSnapshotConfig(mockAPI.URL),
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSnapshotExists(mockAPI.URL, "vbridge_virtual_machine_snapshot.snapshot"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_snapshot.snapshot", "name", "pre-patch"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_snapshot.snapshot", "include_memory", "true"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_snapshot.snapshot", "quiesce", "false"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine_snapshot.snapshot", "warn_after", "72h"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine_snapshot.snapshot", "id"),
+					resource.TestCheckResourceAttrSet("vbridge_virtual_machine_snapshot.snapshot", "created_on"),
+				),
+			},
+			{
+				// WHEN
+				// The VM was read before the snapshot was taken, so its
+				// snapshots only show up after a refresh.
+				RefreshState: true,
+
+				// THEN
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "snapshots.#", "1"),
+					resource.TestCheckResourceAttrPair("vbridge_virtual_machine.vm", "snapshots.0.id", "vbridge_virtual_machine_snapshot.snapshot", "id"),
+					resource.TestCheckResourceAttr("vbridge_virtual_machine.vm", "snapshots.0.name", "pre-patch"),
+				),
+			},
+			{
+				// WHEN
+				ResourceName:      "vbridge_virtual_machine_snapshot.snapshot",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs, ok := s.RootModule().Resources["vbridge_virtual_machine_snapshot.snapshot"]
+					if !ok {
+						return "", fmt.Errorf("resource not found: vbridge_virtual_machine_snapshot.snapshot")
+					}
+					return rs.Primary.Attributes["vm_id"] + "/" + rs.Primary.ID, nil
+				},
+
+				// THEN
+				ImportStateVerifyIgnore: []string{"timeouts"},
+			},
+		},
+	})
+}
+
+// testAccCheckSnapshotExists checks the snapshot in state exists in the API.
+func testAccCheckSnapshotExists(apiURL, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.GetSnapshot(context.Background(), rs.Primary.Attributes["vm_id"], rs.Primary.ID)
+		return err
+	}
+}
+
+// testAccCheckSnapshotDestroy checks every snapshot in state has been removed.
+func testAccCheckSnapshotDestroy(apiURL string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := acctest.Client(apiURL)
+		if err != nil {
+			return err
+		}
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "vbridge_virtual_machine_snapshot" {
+				continue
+			}
+
+			if _, err := client.GetSnapshot(context.Background(), rs.Primary.Attributes["vm_id"], rs.Primary.ID); err == nil {
+				return fmt.Errorf("snapshot %s still exists on VM %s", rs.Primary.ID, rs.Primary.Attributes["vm_id"])
+			}
+		}
+
+		return nil
+	}
+}